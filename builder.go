@@ -1,8 +1,20 @@
 package factory
 
+import "reflect"
+
 // Builder is a struct that implements builder pattern to create a new factory
 type Builder struct {
-	proto interface{}
+	proto     interface{}
+	fGens     []FieldGenFunc
+	beforeGen []HookFunc
+	afterGen  []HookFunc
+	traits    []builderTrait
+	base      *Factory // factory to derive from, set by Factory.ToBuilder
+}
+
+// builderTrait is a named set of field generators defined with Builder.Trait
+type builderTrait struct {
+	name  string
 	fGens []FieldGenFunc
 }
 
@@ -13,12 +25,13 @@ type ForBuilder interface {
 }
 
 type forBuilder struct {
-	g GeneratorFunc
+	g FieldGeneratorBuilder
 	b *Builder
 }
 
 func (f *forBuilder) For(fields ...string) *Builder {
-	return f.b.WithGen(f.g, fields...)
+	f.b.fGens = append(f.b.fGens, f.g.For(fields...))
+	return f.b
 }
 
 // NewBuilder allocates a new factory builder
@@ -26,6 +39,15 @@ func NewBuilder(proto interface{}) *Builder {
 	return &Builder{proto: proto, fGens: []FieldGenFunc{}}
 }
 
+// ToBuilder returns a builder that derives a new factory from f with Build, so the generators,
+// hooks, traits and other settings of f are kept and the ones added to the builder are applied on top.
+// The factory f itself is not changed.
+func (f *Factory) ToBuilder() *Builder {
+	b := NewBuilder(reflect.Zero(f.typ).Interface())
+	b.base = f
+	return b
+}
+
 // WithGen adds new gennerator to builder
 func (b *Builder) WithGen(g GeneratorFunc, fields ...string) *Builder {
 	b.fGens = append(b.fGens, WithGen(g, fields...))
@@ -36,7 +58,24 @@ func (b *Builder) WithGen(g GeneratorFunc, fields ...string) *Builder {
 // with a single method `For` to bind generator to struct fiends
 func (b *Builder) Use(i interface{}, args ...interface{}) ForBuilder {
 	return &forBuilder{
-		g: NewGenerator(i, args...),
+		g: Use(i, args...),
+		b: b,
+	}
+}
+
+// UseSame is like Use but the generator is invoked once per instance
+// and the value is assigned to all the fields
+func (b *Builder) UseSame(i interface{}, args ...interface{}) ForBuilder {
+	return &forBuilder{
+		g: UseSame(i, args...),
+		b: b,
+	}
+}
+
+// UseOptional is like Use but the fields missing in the type of instances are skipped
+func (b *Builder) UseOptional(i interface{}, args ...interface{}) ForBuilder {
+	return &forBuilder{
+		g: UseOptional(i, args...),
 		b: b,
 	}
 }
@@ -46,7 +85,44 @@ func (b *Builder) And(i interface{}, args ...interface{}) ForBuilder {
 	return b.Use(i, args...)
 }
 
+// BeforeCreate adds a hook to call before field generators
+func (b *Builder) BeforeCreate(hook HookFunc) *Builder {
+	b.beforeGen = append(b.beforeGen, hook)
+	return b
+}
+
+// AfterCreate adds a hook to call after field generators
+func (b *Builder) AfterCreate(hook HookFunc) *Builder {
+	b.afterGen = append(b.afterGen, hook)
+	return b
+}
+
+// Trait defines a named trait. The function fn receives a sub-builder
+// to record field generators of the trait with Use/For calls.
+func (b *Builder) Trait(name string, fn func(b *Builder)) *Builder {
+	sub := NewBuilder(b.proto)
+	fn(sub)
+	b.traits = append(b.traits, builderTrait{name: name, fGens: sub.fGens})
+	return b
+}
+
 // Build create a new factory
 func (b *Builder) Build() *Factory {
-	return NewFactory(b.proto, b.fGens...)
+	var f *Factory
+	if b.base != nil {
+		// clone so that hooks and traits do not leak into the base factory
+		f = b.base.Clone().Derive(b.fGens...)
+	} else {
+		f = NewFactory(b.proto, b.fGens...)
+	}
+	for _, hook := range b.beforeGen {
+		f.BeforeCreate(hook)
+	}
+	for _, hook := range b.afterGen {
+		f.AfterCreate(hook)
+	}
+	for _, trait := range b.traits {
+		f.RegisterTrait(trait.name, trait.fGens...)
+	}
+	return f
 }
@@ -32,4 +32,81 @@ var _ = Describe("Builder", func() {
 		Ω(u.Age).Should(And(BeNumerically(">=", 20), BeNumerically("<", 50)))
 		Ω(u.Married).Should(BelongTo(true, false))
 	})
+
+	It("should assign the same value to fields with UseSame", func() {
+		u := factory.NewBuilder(User{}).UseSame(randomdata.FirstName, randomdata.Male).For("FirstName", "Username").Build().MustCreate().(*User)
+		Ω(u.Username).Should(Equal(u.FirstName))
+	})
+
+	It("should register AfterCreate hooks", func() {
+		f := factory.NewBuilder(
+			User{},
+		).Use("John").For(
+			"FirstName",
+		).AfterCreate(func(ctx factory.Ctx) error {
+			u := ctx.Instance.(*User)
+			u.Username = u.FirstName + "1"
+			return nil
+		}).Build()
+
+		u := f.MustCreate().(*User)
+		Ω(u.Username).Should(Equal("John1"))
+	})
+
+	It("should register BeforeCreate hooks", func() {
+		calls := []string{}
+		f := factory.NewBuilder(
+			User{},
+		).BeforeCreate(func(ctx factory.Ctx) error {
+			calls = append(calls, "before")
+			return nil
+		}).Use(func() string {
+			calls = append(calls, "gen")
+			return "John"
+		}).For(
+			"FirstName",
+		).AfterCreate(func(ctx factory.Ctx) error {
+			calls = append(calls, "after")
+			return nil
+		}).Build()
+
+		f.MustCreate()
+		Ω(calls).Should(Equal([]string{"before", "gen", "after"}))
+	})
+
+	It("should make builder from factory", func() {
+		base := factory.NewFactory(User{}, factory.Use("john").For("Username"), factory.Use(30).For("Age"))
+		base.RegisterTrait("married", factory.Use(true).For("Married"))
+
+		f := base.ToBuilder().Use("jane").For("Username").AfterCreate(func(ctx factory.Ctx) error {
+			ctx.Instance.(*User).Comment = "variant"
+			return nil
+		}).Build()
+
+		u := f.MustCreate(factory.WithTraits("married")).(*User)
+		Ω(u.Username).Should(Equal("jane"))
+		Ω(u.Age).Should(Equal(30))
+		Ω(u.Married).Should(BeTrue())
+		Ω(u.Comment).Should(Equal("variant"))
+
+		u = base.MustCreate().(*User)
+		Ω(u.Username).Should(Equal("john"))
+		Ω(u.Comment).Should(BeEmpty())
+	})
+
+	It("should define traits", func() {
+		f := factory.NewBuilder(
+			User{},
+		).Use("john").For(
+			"Username",
+		).Trait("admin", func(b *factory.Builder) {
+			b.Use("admin").For("Username").And(true).For("Married")
+		}).Build()
+
+		Ω(f.MustCreate().(*User).Username).Should(Equal("john"))
+
+		u := f.MustCreate(factory.WithTraits("admin")).(*User)
+		Ω(u.Username).Should(Equal("admin"))
+		Ω(u.Married).Should(BeTrue())
+	})
 })
@@ -1,6 +1,9 @@
 package factory_test
 
 import (
+	"errors"
+	"fmt"
+
 	. "github.com/kolach/go-factory"
 	. "github.com/kolach/gomega-matchers"
 	. "github.com/onsi/ginkgo"
@@ -19,12 +22,25 @@ const (
 	Red
 )
 
+type Years int
+
 type S struct {
 	Slice []int
 	Map   map[int]string
 	PStr  *string
 	PStr2 *string
 	Color Color
+	Int64 int64
+	Years Years
+	Str   string
+}
+
+type Nums struct {
+	Small  int8
+	Count  uint
+	Whole  int
+	Ratio  float32
+	PSmall *int8
 }
 
 func genSlice() []int {
@@ -76,6 +92,32 @@ var _ = Describe("CrashTest", func() {
 			Ω(s.Color).Should(Equal(Red))
 		})
 
+		It("should select enum values", func() {
+			err := f.SetFields(&s, Use(EnumOf(White, Red)).For("Color"))
+			Ω(err).Should(BeNil())
+			Ω(s.Color).Should(BelongTo(White, Red))
+		})
+
+		It("should check enum type", func() {
+			Ω(func() { EnumOf(White, 2) }).Should(PanicWithError(errors.New("expect enum values of type factory_test.Color but was: int")))
+			Ω(func() { NewFactory(S{}, Use(EnumOf(Years(1), Years(2))).For("Color")) }).Should(PanicWithError(errors.New(`field "Color": expect field of enum type factory_test.Years but was: factory_test.Color`)))
+
+			// not bound with Use the field is checked on generation
+			_, err := f.Create(WithGen(NewGenerator(EnumOf(Years(1), Years(2))), "Color"))
+			Ω(err).Should(MatchError(`field "Color": expect field of enum type factory_test.Years but was: factory_test.Color`))
+		})
+
+		It("should select enum values for interface fields", func() {
+			doc := *NewFactory(map[string]interface{}{}, Use(EnumOf(White, Red)).For("color")).MustCreate().(*map[string]interface{})
+			Ω(doc["color"]).Should(BelongTo(White, Red))
+
+			doc = *NewFactory(map[string]interface{}{}, WithGen(NewGenerator(EnumOf(White, Red)), "color")).MustCreate().(*map[string]interface{})
+			Ω(doc["color"]).Should(BelongTo(White, Red))
+
+			type Painted struct{ Color fmt.Stringer }
+			Ω(func() { NewFactory(Painted{}, Use(EnumOf(White, Red)).For("Color")) }).Should(PanicWithError(errors.New(`field "Color": expect field of enum type factory_test.Color but was: fmt.Stringer`)))
+		})
+
 		It("should work with canonical generator functions", func() {
 			err := f.SetFields(&s, Use(func(ctx Ctx) (interface{}, error) { return Red, nil }).For("Color"))
 			Ω(err).Should(BeNil())
@@ -96,4 +138,123 @@ var _ = Describe("CrashTest", func() {
 		Ω(s.Slice).Should(BeNil())
 		Ω(s.Map).Should(BeNil())
 	})
+
+	Context("strict nil", func() {
+		It("should return error on nil for not nilable fields", func() {
+			strict := f.StrictNil(true)
+			_, err := strict.Create(Use(nil).For("Str"))
+			Ω(err).Should(MatchError(`field "Str": cannot assign nil to string`))
+
+			_, err = strict.Create(Use(func() *string { return nil }).For("Color"))
+			Ω(err).Should(MatchError(`field "Color": cannot assign nil to factory_test.Color`))
+
+			Ω(f.MustCreate(Use(nil).For("Str")).(*S).Str).Should(BeEmpty())
+		})
+
+		It("should set nil to nilable fields", func() {
+			s, err := f.StrictNil(true).Create(Use(nil).For("PStr", "Slice", "Map"))
+			Ω(err).Should(BeNil())
+			Ω(s.(*S).PStr).Should(BeNil())
+		})
+	})
+
+	Context("conversion", func() {
+		It("should convert values to field types", func() {
+			s := f.MustCreate(
+				Use(func() int32 { return 5 }).For("Int64"),
+				Use(func() int { return 30 }).For("Years"),
+				Use(2).For("Color"),
+			).(*S)
+			Ω(s.Int64).Should(Equal(int64(5)))
+			Ω(s.Years).Should(Equal(Years(30)))
+			Ω(s.Color).Should(Equal(Red))
+		})
+
+		It("should return error on not convertible values", func() {
+			_, err := f.Create(Use("foo").For("Int64"))
+			Ω(err).Should(MatchError(`field "Int64": cannot assign string to int64`))
+		})
+
+		It("should not convert integers to strings", func() {
+			_, err := f.Create(Use(65).For("Str"))
+			Ω(err).Should(MatchError(`field "Str": cannot assign int to string`))
+		})
+
+		It("should convert numbers that fit into field types", func() {
+			n := NewFactory(Nums{},
+				Use(int64(-128)).For("Small"),
+				Use(7).For("Count"),
+				Use(2.0).For("Whole"),
+				Use(0.1).For("Ratio"),
+				Use(127).For("PSmall"),
+			).MustCreate().(*Nums)
+			Ω(n.Small).Should(Equal(int8(-128)))
+			Ω(n.Count).Should(Equal(uint(7)))
+			Ω(n.Whole).Should(Equal(2))
+			Ω(n.Ratio).Should(Equal(float32(0.1)))
+			Ω(*n.PSmall).Should(Equal(int8(127)))
+		})
+
+		It("should return error on lossy numeric conversions", func() {
+			nums := NewFactory(Nums{})
+			_, err := nums.Create(Use(int64(300)).For("Small"))
+			Ω(err).Should(MatchError(`field "Small": cannot convert 300 of int64 to int8 without loss`))
+			_, err = nums.Create(Use(-1).For("Count"))
+			Ω(err).Should(MatchError(`field "Count": cannot convert -1 of int to uint without loss`))
+			_, err = nums.Create(Use(1.5).For("Whole"))
+			Ω(err).Should(MatchError(`field "Whole": cannot convert 1.5 of float64 to int without loss`))
+			_, err = nums.Create(Use(1e300).For("Ratio"))
+			Ω(err).Should(MatchError(`field "Ratio": cannot convert 1e+300 of float64 to float32 without loss`))
+			_, err = nums.Create(Use(128).For("PSmall"))
+			Ω(err).Should(MatchError(`field "PSmall": cannot convert 128 of int to int8 without loss`))
+		})
+
+		It("should allocate pointers to primitive values", func() {
+			type Opts struct {
+				Count   *int
+				Name    *string
+				Enabled *bool
+				Limit   *int64
+				Color   *Color
+			}
+			o := NewFactory(Opts{},
+				Use(42).For("Count", "Limit"),
+				Use("foo").For("Name"),
+				Use(true).For("Enabled"),
+				Use(Red).For("Color"),
+			).MustCreate().(*Opts)
+			Ω(*o.Count).Should(Equal(42))
+			Ω(*o.Limit).Should(Equal(int64(42)))
+			Ω(*o.Name).Should(Equal("foo"))
+			Ω(*o.Enabled).Should(BeTrue())
+			Ω(*o.Color).Should(Equal(Red))
+		})
+
+		It("should allocate a new pointer for every instance", func() {
+			created := f.MustCreateN(2, Use("foo").For("PStr"))
+			Ω(created[0].(*S).PStr).ShouldNot(BeIdenticalTo(created[1].(*S).PStr))
+		})
+
+		It("should return error on values not convertible to pointer element", func() {
+			_, err := f.Create(Use(65).For("PStr"))
+			Ω(err).Should(MatchError(`field "PStr": cannot assign int to *string`))
+		})
+	})
+
+	Context("type mismatch", func() {
+		It("should return error naming the field", func() {
+			_, err := f.Create(Use(genSlice).For("Map"))
+			Ω(err).Should(MatchError(`field "Map": cannot assign []int to map[int]string`))
+		})
+
+		It("should return error on dereferenced pointer of wrong type", func() {
+			_, err := f.Create(Use(genPStr).For("Int64"))
+			Ω(err).Should(MatchError(`field "Int64": cannot assign *string to int64`))
+		})
+
+		It("should set zero value on nil pointer to value field", func() {
+			s := f.MustCreate(Use(func() *string { return nil }).For("Str")).(*S)
+			Ω(s.Str).Should(BeEmpty())
+		})
+	})
 })
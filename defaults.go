@@ -0,0 +1,108 @@
+package factory
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// timeType is the type of time.Time fields that are left at zero value by defaults
+var timeType = reflect.TypeOf(time.Time{})
+
+// WithDefaults produces a new factory that fills in the exported fields without generators,
+// including the fields of nested structs, with random values of their kind: strings of
+// 8 lowercase letters, numbers in [0, 100) and booleans. The fields of other kinds, like
+// slices, maps, pointers or time.Time, are left at zero value. Explicit generators and
+// proto values always win over defaults, including the ones added later with Derive.
+func (f *Factory) WithDefaults() *Factory {
+	if f.typ.Kind() != reflect.Struct {
+		return f
+	}
+
+	sample := f.new()
+	defaults := []fieldWithGen{}
+	for _, path := range f.defaultFields(f.typ, "") {
+		gen := defaultGen(f.typ, path)
+		defaults = append(defaults, WithGen(gen, path).kinded(kindDefault)(sample)...)
+	}
+
+	d := *f
+	d.fieldGens = append(defaults, f.fieldGens...)
+	d.bindRegistry()
+	return &d
+}
+
+// defaultFields lists the paths of exported fields of struct type typ nested at prefix
+// that have no generators and a default of their kind
+func (f *Factory) defaultFields(typ reflect.Type, prefix string) []string {
+	paths := []string{}
+	for i := 0; i < typ.NumField(); i++ {
+		sField := typ.Field(i)
+		path := prefix + sField.Name
+		if sField.PkgPath != "" || f.hasParentGen(path) {
+			continue
+		}
+
+		switch {
+		case sField.Type.Kind() == reflect.Struct && sField.Type != timeType:
+			paths = append(paths, f.defaultFields(sField.Type, path+".")...)
+		case defaultKind(sField.Type.Kind()):
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// hasParentGen checks if the factory has generator of the field or any of its parent fields
+func (f *Factory) hasParentGen(path string) bool {
+	for _, fg := range f.fieldGens {
+		if fg.Name == path || strings.HasPrefix(path, fg.Name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultKind checks if there is default generator of the kind
+func defaultKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// defaultGen makes generator of random values of the kind of field at path in struct type typ
+func defaultGen(typ reflect.Type, path string) GeneratorFunc {
+	for _, name := range strings.Split(path, ".") {
+		sField, _ := typ.FieldByName(name)
+		typ = sField.Type
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return func(ctx Ctx) (interface{}, error) {
+			b := make([]byte, 8)
+			for i := range b {
+				b[i] = byte('a' + randIntn(ctx, 26))
+			}
+			return string(b), nil
+		}
+	case reflect.Bool:
+		return func(ctx Ctx) (interface{}, error) {
+			return randIntn(ctx, 2) == 1, nil
+		}
+	case reflect.Float32, reflect.Float64:
+		return func(ctx Ctx) (interface{}, error) {
+			return randFloat64(ctx) * 100, nil
+		}
+	default:
+		return func(ctx Ctx) (interface{}, error) {
+			return randIntn(ctx, 100), nil
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package factory_test
+
+import (
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kolach/go-factory"
+)
+
+type Level int8
+
+type Settings struct {
+	Name    string
+	Enabled bool
+	Level   Level
+	Port    uint16
+	Ratio   float32
+	Tags    []string
+	Owner   *User
+	Updated time.Time
+	Address Address
+	secret  string
+}
+
+var _ = Describe("WithDefaults", func() {
+	It("should fill in fields without generators with defaults of their kind", func() {
+		s := NewFactory(Settings{}).WithDefaults().MustCreate().(*Settings)
+		Ω(s.Name).Should(MatchRegexp(`^[a-z]{8}$`))
+		Ω(s.Level).Should(And(BeNumerically(">=", 0), BeNumerically("<", 100)))
+		Ω(s.Port).Should(BeNumerically("<", 100))
+		Ω(s.Ratio).Should(And(BeNumerically(">=", 0), BeNumerically("<", 100)))
+		Ω(s.Address.City).Should(MatchRegexp(`^[a-z]{8}$`))
+		Ω(s.Address.Street).Should(MatchRegexp(`^[a-z]{8}$`))
+		Ω(s.Tags).Should(BeNil())
+		Ω(s.Owner).Should(BeNil())
+		Ω(s.Updated.IsZero()).Should(BeTrue())
+		Ω(s.secret).Should(BeEmpty())
+	})
+
+	It("should keep explicit generators and proto values", func() {
+		f := NewFactory(Settings{Port: 8080},
+			Use("main").For("Name"),
+			Use("CDMX").For("Address.City"),
+		).WithDefaults()
+		s := f.MustCreate().(*Settings)
+		Ω(s.Name).Should(Equal("main"))
+		Ω(s.Port).Should(Equal(uint16(8080)))
+		Ω(s.Address.City).Should(Equal("CDMX"))
+		Ω(s.Address.Street).Should(MatchRegexp(`^[a-z]{8}$`))
+
+		s = f.MustCreate(Use("aux").For("Name")).(*Settings)
+		Ω(s.Name).Should(Equal("aux"))
+	})
+
+	It("should leave nested struct with generator to it", func() {
+		s := NewFactory(Settings{}, Use(Address{City: "Tulum"}).For("Address")).WithDefaults().MustCreate().(*Settings)
+		Ω(s.Address).Should(Equal(Address{City: "Tulum"}))
+	})
+
+	It("should draw defaults from factory random source", func() {
+		f := NewFactory(Settings{}).WithDefaults()
+		create := func() interface{} {
+			return f.WithRand(rand.New(rand.NewSource(7))).MustCreate()
+		}
+		Ω(create()).Should(Equal(create()))
+	})
+
+	It("should describe default generators", func() {
+		f := NewFactory(Address{}, Use("CDMX").For("City")).WithDefaults()
+		Ω(f.DescribeFields()).Should(ContainSubstring("Street: default"))
+		Ω(f.DescribeFields()).Should(ContainSubstring("City: value"))
+	})
+})
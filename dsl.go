@@ -1,16 +1,151 @@
 package factory
 
+import (
+	"reflect"
+	"strings"
+)
+
 // FieldGeneratorBuilder is DSL build chain pattern
 type FieldGeneratorBuilder struct {
 	generator GeneratorFunc
+	kind      genKind     // kind of generator for diagnostics
+	same      bool        // assign the same value to all fields
+	optional  bool        // skip the fields missing in the type
+	value     interface{} // the value of static value generator
 }
 
-// Use this value/function/factory For that field(s)
+// Use this value/function/factory For that field(s). The values holding references,
+// like slices and maps, are assigned as is, so all the instances share them.
 func Use(i interface{}, args ...interface{}) (g FieldGeneratorBuilder) {
-	return FieldGeneratorBuilder{NewGenerator(i, args...)}
+	gen, kind := newGenerator(i, args...)
+	return FieldGeneratorBuilder{generator: gen, kind: kind, value: i}
+}
+
+// UseSame is like Use but the generator is invoked once per instance and
+// the value is assigned to all the fields listed in For.
+func UseSame(i interface{}, args ...interface{}) (g FieldGeneratorBuilder) {
+	gen, kind := newGenerator(i, args...)
+	return FieldGeneratorBuilder{generator: gen, kind: kind, same: true, value: i}
+}
+
+// UseOptional is like Use but the fields listed in For that the type of instances lacks are skipped
+// instead of panic, so the same field generators can be shared by factories of related types.
+// The fields that exist but can not be set still make it panic.
+func UseOptional(i interface{}, args ...interface{}) (g FieldGeneratorBuilder) {
+	g = Use(i, args...)
+	g.optional = true
+	return g
 }
 
 // For creates FieldGenFunc for each provided field
 func (g FieldGeneratorBuilder) For(field ...string) FieldGenFunc {
-	return WithGen(g.generator, field...)
+	if g.optional {
+		g.optional = false
+		return func(sample reflect.Value) []fieldWithGen {
+			present := []string{}
+			for _, name := range field {
+				if hasField(sample.Type().Elem(), name) {
+					present = append(present, name)
+				}
+			}
+			if len(present) == 0 {
+				return nil
+			}
+			return g.For(present...)(sample)
+		}
+	}
+
+	if !g.same || len(field) < 2 {
+		return g.bind(WithGen(g.generator, field...))
+	}
+
+	// the first field is generated, the rest copy its value
+	first := field[0]
+	return func(sample reflect.Value) []fieldWithGen {
+		fieldGens := g.bind(WithGen(g.generator, first))(sample)
+		copyGens := WithGen(CopyField(first), field[1:]...).DependsOn(first).kinded(kindCopy)
+		return append(fieldGens, copyGens(sample)...)
+	}
+}
+
+// ForIndex creates FieldGenFunc for the field addressed by the index path, like reflect.StructField.Index,
+// so the field is not looked up by name. See WithGenIndex.
+func (g FieldGeneratorBuilder) ForIndex(index []int) FieldGenFunc {
+	return g.bind(WithGenIndex(g.generator, index))
+}
+
+// bind labels the field generators with kind of generator, marks the ones of static values
+// and checks the fields of enum generators
+func (g FieldGeneratorBuilder) bind(fgf FieldGenFunc) FieldGenFunc {
+	fgf = fgf.kinded(g.kind)
+	if g.kind == kindValue {
+		fgf = fgf.staticValue(g.value)
+	}
+	if enum, ok := g.value.(Enum); ok {
+		fgf = fgf.checkField(enum.checkField)
+	}
+	return fgf
+}
+
+// hasField checks if the struct type typ has the field addressed by dotted path.
+// Any key is considered present in maps.
+func hasField(typ reflect.Type, path string) bool {
+	if typ.Kind() == reflect.Map {
+		return true
+	}
+	for _, name := range strings.Split(path, ".") {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			return false
+		}
+		sField, ok := typ.FieldByName(name)
+		if !ok {
+			return false
+		}
+		typ = sField.Type
+	}
+	return true
+}
+
+// Omit leaves the listed fields and their nested fields at zero value removing
+// the factory generators of the fields. Field generators passed along with it are kept.
+func Omit(fields ...string) FieldGenFunc {
+	return func(reflect.Value) []fieldWithGen {
+		return []fieldWithGen{{keep: func(name string) bool {
+			return !matchFields(name, fields)
+		}}}
+	}
+}
+
+// Only leaves only the generators of listed fields and their nested fields
+// removing the rest of the factory generators. Field generators passed along with it are kept.
+func Only(fields ...string) FieldGenFunc {
+	return func(reflect.Value) []fieldWithGen {
+		return []fieldWithGen{{keep: func(name string) bool {
+			return matchFields(name, fields)
+		}}}
+	}
+}
+
+// Preserve leaves the listed fields and their nested fields as they are if they are already set to
+// non-zero value on the instance passed to SetFields, so only the blank fields are generated.
+// Without arguments it preserves all the fields. Field generators passed along with it are kept.
+func Preserve(fields ...string) FieldGenFunc {
+	return func(reflect.Value) []fieldWithGen {
+		return []fieldWithGen{{keepSet: func(name string) bool {
+			return len(fields) == 0 || matchFields(name, fields)
+		}}}
+	}
+}
+
+// matchFields checks if the field name or its parent field is in the list
+func matchFields(name string, fields []string) bool {
+	for _, field := range fields {
+		if name == field || strings.HasPrefix(name, field+".") {
+			return true
+		}
+	}
+	return false
 }
@@ -1,43 +1,191 @@
 package factory
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"math/rand"
 	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 )
 
 // Ctx is the context in which the field value is being generated
 type Ctx struct {
-	Field    string      // current field name for which the value is generated
-	Instance interface{} // the result instance to that the field belongs
-	Factory  *Factory    // the reference to the Factory
+	Field    string          // current field name for which the value is generated
+	Instance interface{}     // the result instance to that the field belongs
+	Factory  *Factory        // the reference to the Factory
+	Rand     *rand.Rand      // random source of the factory, the package-global one if not set
+	Context  context.Context // context the instance is being created in
+	Index    int             // index of the instance in a batch, 0 for single instance
+	Parent   interface{}     // the instance the sub-factory is creating a nested instance for, nil at top level
+}
+
+// FromProto reports whether the value of the field of instance being created is taken from the proto object,
+// see Factory.FromProto. Generators can check it for other fields, like If predicates deciding whether
+// to fill in the field depending on the origin of another one.
+func (ctx Ctx) FromProto(field string) bool {
+	return ctx.Factory != nil && ctx.Factory.FromProto(field)
 }
 
 // GeneratorFunc describes field generator signatures
 type GeneratorFunc func(ctx Ctx) (interface{}, error)
 
+// FieldError is the error of generating the field value
+type FieldError struct {
+	Field string // name of the field, dotted path for the fields of nested structs
+	Err   error  // error returned by generator
+}
+
+// Error implements error interface
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %q: %v", e.Field, e.Err)
+}
+
+// Unwrap returns the error returned by generator
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// fieldError wraps err into FieldError unless it's already the one of the field
+func fieldError(field string, err error) error {
+	if fe, ok := err.(*FieldError); ok && fe.Field == field {
+		return err
+	}
+	return &FieldError{Field: field, Err: err}
+}
+
+// Unset is the value generator returns to leave the field as it is, for example
+// at the value set by prototype, unlike nil that sets the field to zero value.
+var Unset = &unset{}
+
+type unset struct{}
+
+// HookFunc describes signature of callbacks invoked on instance creation
+type HookFunc func(ctx Ctx) error
+
 // FieldGenFunc is the signature of field generator factory.
 type FieldGenFunc func(sample reflect.Value) []fieldWithGen
 
 // fieldWithGen is a tuple that keeps together struct field and generator function.
 type fieldWithGen struct {
 	*reflect.StructField
-	gen GeneratorFunc
+	gen       GeneratorFunc
+	traits    []string               // names of traits to apply, set for WithTraits placeholder only
+	err       error                  // field resolution error, set for WithGenE placeholder only
+	deps      []string               // names of fields to generate before this one
+	keep      func(name string) bool // filter of base generators, set for Omit and Only placeholders only
+	keepSet   func(name string) bool // filter of fields to keep if already set, set for Preserve placeholder only
+	kind      genKind                // kind of generator for diagnostics
+	static    reflect.Value          // value of static generator adapted to the field type, invalid for others
+	raw       interface{}            // value of static generator as is
+	fromProto bool                   // generator is taken from the proto object
 }
 
 // Factory produces new objects according to specified generators
 type Factory struct {
-	typ       reflect.Type   // type information about generated instances
-	fieldGens []fieldWithGen // field / generator tuples
-	callDepth int            // factory call depth
+	typ       reflect.Type              // type information about generated instances
+	fieldGens []fieldWithGen            // field / generator tuples
+	callDepth int                       // factory call depth
+	maxDepth  int                       // max call depth to generate instances at, 0 for no limit
+	rand      *rand.Rand                // random source, nil to use the global one
+	beforeGen []HookFunc                // hooks to call before field generators
+	afterGen  []HookFunc                // hooks to call after field generators
+	traits    map[string][]FieldGenFunc // named sets of field generators
+	validate  []func(interface{}) error // validators of created instances
+	collect   []func(interface{})       // observers of created instances
+	preserve  []func(string) bool       // filters of fields to leave as they are if already set
+	context   context.Context           // context of the current creation, set on dive
+	index     int                       // index of the instance being created in a batch
+	parent    interface{}               // instance the nested instance is being created for, set on dive
+	strictNil bool                      // fail on nil values generated for not nilable fields
+	registry  *Registry                 // factories of the fields without generators
+	regGens   []fieldWithGen            // registry generators followed by fieldGens, nil if there are none
+	resets    *resetSet                 // stateful generators to reset, shared with derived factories
 }
 
 // dive clones factory with incremented call depth
 func (f *Factory) dive() *Factory {
-	return &Factory{
-		typ:       f.typ,
-		fieldGens: f.fieldGens,
-		callDepth: f.callDepth + 1,
+	d := *f
+	d.callDepth++
+	return &d
+}
+
+// self returns the factory of nested calls made while creating instance i in context c
+func (f *Factory) self(c context.Context, i interface{}) *Factory {
+	d := f.dive()
+	d.context = c
+	d.parent = i
+	return d
+}
+
+// Clone returns an independent copy of the factory, so registering
+// hooks and traits on the clone does not affect the original factory.
+func (f *Factory) Clone() *Factory {
+	c := *f
+	c.fieldGens = append([]fieldWithGen(nil), f.fieldGens...)
+	c.beforeGen = append([]HookFunc(nil), f.beforeGen...)
+	c.afterGen = append([]HookFunc(nil), f.afterGen...)
+	c.validate = append([]func(interface{}) error(nil), f.validate...)
+	c.collect = append(([]func(interface{}))(nil), f.collect...)
+	c.traits = make(map[string][]FieldGenFunc, len(f.traits))
+	for name, trait := range f.traits {
+		c.traits[name] = append([]FieldGenFunc(nil), trait...)
 	}
+	return &c
+}
+
+// WithRand produces a new factory that draws random values from r.
+// Use it with a seeded source to make generated objects reproducible.
+// The source is guarded by a mutex so the factory is safe for concurrent use,
+// but r itself should not be used elsewhere meanwhile.
+func (f *Factory) WithRand(r *rand.Rand) *Factory {
+	d := *f
+	d.rand = lockedRand(r)
+	return &d
+}
+
+// WithValidator produces a new factory that validates every created instance with fn
+// after all the field generators and AfterCreate hooks. The error returned by fn is returned
+// by SetFields and Create. It fits Struct method of go-playground/validator and alike.
+func (f *Factory) WithValidator(fn func(instance interface{}) error) *Factory {
+	d := *f
+	d.validate = append(f.validate[:len(f.validate):len(f.validate)], fn)
+	return &d
+}
+
+// WithCollector produces a new factory that passes every successfully created instance to fn
+// after hooks and validators, including the instances created recursively and in batches.
+// Unlike AfterCreate hooks collectors only observe the instances, for example to count them in tests.
+// The fn is not guarded by the factory, so it must be safe for concurrent use if the factory is.
+func (f *Factory) WithCollector(fn func(instance interface{})) *Factory {
+	d := *f
+	d.collect = append(f.collect[:len(f.collect):len(f.collect)], fn)
+	return &d
+}
+
+// StrictNil produces a new factory that returns an error if generator yields nil or nil pointer
+// for a field that can't be nil, like string or struct, instead of setting the field to zero value.
+// Note that generators like Maybe and If yield nil to leave the field at zero value.
+func (f *Factory) StrictNil(strict bool) *Factory {
+	d := *f
+	d.strictNil = strict
+	return &d
+}
+
+// randSource returns the random source of the factory or the package-global one if it's not set
+func (f *Factory) randSource() *rand.Rand {
+	if f.rand != nil {
+		return f.rand
+	}
+	return globalRand
+}
+
+// Type returns the type of instances the factory creates
+func (f *Factory) Type() reflect.Type {
+	return f.typ
 }
 
 // CallDepth returns factory call depth
@@ -45,92 +193,398 @@ func (f *Factory) CallDepth() int {
 	return f.callDepth
 }
 
+// WithMaxDepth produces a new factory that stops recursion at call depth n: recursive
+// SetFields calls deeper than n leave the instance untouched and Create returns nil pointer.
+// Zero n means no limit.
+func (f *Factory) WithMaxDepth(n int) *Factory {
+	d := *f
+	d.maxDepth = n
+	return &d
+}
+
+// MaxDepth returns max call depth set by WithMaxDepth
+func (f *Factory) MaxDepth() int {
+	return f.maxDepth
+}
+
+// Fields returns the names of fields the factory generates values for, including the fields
+// of nested structs as dotted paths. The names are listed in order of registration
+// unless DependsOn makes some fields generated later.
+func (f *Factory) Fields() []string {
+	seen := make(map[string]bool, len(f.fieldGens))
+	names := make([]string, 0, len(f.fieldGens))
+	for _, fg := range f.fieldGens {
+		if !seen[fg.Name] {
+			seen[fg.Name] = true
+			names = append(names, fg.Name)
+		}
+	}
+	return names
+}
+
+// DescribeFields returns a listing of field generators, one per line, with the kind of generator
+// like value, select, func or factory and the fields it depends on. It helps to find out why
+// two factories produce different objects.
+func (f *Factory) DescribeFields() string {
+	var b strings.Builder
+	for _, fg := range f.fieldGens {
+		fmt.Fprintf(&b, "%s: %s", fg.Name, fg.kind)
+		if len(fg.deps) > 0 {
+			fmt.Fprintf(&b, " (depends on %s)", strings.Join(fg.deps, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// FromProto reports whether the value of the field is taken from the proto object rather than
+// generated by the field generators passed to the factory. Zero value fields of the proto object
+// are not taken unless the proto object is wrapped with Proto, so it reports false for them.
+// The generators passed along with the proto object override it, so it reports false for them too.
+func (f *Factory) FromProto(field string) bool {
+	fromProto := false
+	// the last generator of the field sets its value
+	for _, fg := range f.fieldGens {
+		if fg.Name == field {
+			fromProto = fg.fromProto
+		}
+	}
+	return fromProto
+}
+
+// tooDeep checks if the next call goes deeper than max call depth
+func (f *Factory) tooDeep() bool {
+	return f.maxDepth > 0 && f.callDepth >= f.maxDepth
+}
+
 // Derive produces a new factory overriding field generators
 // with the list provided.
 func (f *Factory) Derive(fieldGenFuncs ...FieldGenFunc) *Factory {
-	// Create new generators and lookup map to fast find generator by firld name
-	newGenList := make([]fieldWithGen, 0, len(fieldGenFuncs))
-	newGensMap := make(map[string]GeneratorFunc)
-	sample := f.new()
-	for _, fieldGenFunc := range fieldGenFuncs {
-		for _, fg := range fieldGenFunc(sample) {
-			newGensMap[fg.Name] = fg.gen
-			newGenList = append(newGenList, fg)
+	d, err := f.derive(fieldGenFuncs...)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// With produces a new factory generating the field with the value, function or factory
+// like Use(value, args...).For(field). Calls can be chained to override several fields.
+func (f *Factory) With(field string, value interface{}, args ...interface{}) *Factory {
+	return f.Derive(Use(value, args...).For(field))
+}
+
+// derive is Derive returning an error instead of panic
+func (f *Factory) derive(fieldGenFuncs ...FieldGenFunc) (*Factory, error) {
+	newGenList, filters, preserve, err := f.makeFieldGens(f.new(), fieldGenFuncs)
+	if err != nil {
+		return nil, err
+	}
+
+	// filter out base generators
+	baseGens := f.fieldGens
+	if len(filters) > 0 {
+		baseGens = make([]fieldWithGen, 0, len(f.fieldGens))
+		for _, fg := range f.fieldGens {
+			if keepFieldGen(fg.Name, filters) {
+				baseGens = append(baseGens, fg)
+			}
 		}
 	}
 
+	fieldGens, err := f.sortFieldGens(overrideFieldGens(baseGens, newGenList))
+	if err != nil {
+		return nil, err
+	}
+
+	// inherit everything else including current call depth
+	d := *f
+	d.fieldGens = fieldGens
+	d.preserve = append(f.preserve[:len(f.preserve):len(f.preserve)], preserve...)
+	d.bindRegistry()
+	return &d, nil
+}
+
+// overrideFieldGens overrides base field generators with the generators of the same fields
+// from the new list and appends the rest of the new generators. The last new generator of a field wins.
+// The overridden fields keep the position of their first base generator, the rest of their base
+// generators are dropped. The new fields are appended in order of their first appearance in the new list.
+func overrideFieldGens(baseGens, newGenList []fieldWithGen) []fieldWithGen {
+	// lookup map to fast find the last generator of the field
+	newGensMap := make(map[string]fieldWithGen, len(newGenList))
+	for _, fg := range newGenList {
+		newGensMap[fg.Name] = fg
+	}
+
 	// result generators for a new factory
-	fieldGens := make([]fieldWithGen, len(f.fieldGens))
+	fieldGens := make([]fieldWithGen, 0, len(baseGens)+len(newGenList))
+	// fields that are already in result
+	added := make(map[string]bool, len(baseGens)+len(newGenList))
 
 	// 1. copy or override original field generators
-	for i, fg := range f.fieldGens {
-		if gen, ok := newGensMap[fg.Name]; ok {
-			delete(newGensMap, fg.Name)
-			fg.gen = gen
+	for _, fg := range baseGens {
+		if newFg, ok := newGensMap[fg.Name]; ok {
+			if added[fg.Name] {
+				continue
+			}
+			fg.gen, fg.deps, fg.kind, fg.fromProto = newFg.gen, newFg.deps, newFg.kind, newFg.fromProto
+			fg.static, fg.raw = newFg.static, newFg.raw
 		}
-		fieldGens[i] = fg
+		added[fg.Name] = true
+		fieldGens = append(fieldGens, fg)
 	}
 
 	// 2. append new field generators
 	for _, fg := range newGenList {
-		if _, ok := newGensMap[fg.Name]; ok {
-			fieldGens = append(fieldGens, fg)
+		if !added[fg.Name] {
+			added[fg.Name] = true
+			fieldGens = append(fieldGens, newGensMap[fg.Name])
 		}
 	}
 
-	return &Factory{
-		callDepth: f.callDepth, // inherit currenet call depth
-		fieldGens: fieldGens,   // set new generators
-		typ:       f.typ,
+	return fieldGens
+}
+
+// Merge produces a new factory with field generators of both factories,
+// generators of factory b override the generators of the same fields of factory f.
+// The hooks, validators and collectors of factory b are called after the ones of factory f and its traits
+// win on conflicting names. The random source, max depth and registry of factory b win if they are set,
+// see WithRand, WithMaxDepth and WithRegistry, and the merged factory is strict about nils if either is.
+// The call depth and the stateful generators to reset are the ones of factory f.
+// It panics if factories produce different types.
+func (f *Factory) Merge(b *Factory) *Factory {
+	if f.typ != b.typ {
+		panic(fmt.Errorf("can not merge %s factory with %s factory", f.typ, b.typ))
+	}
+
+	fieldGens, err := f.sortFieldGens(overrideFieldGens(f.fieldGens, b.fieldGens))
+	if err != nil {
+		panic(err)
 	}
+
+	m := f.Clone()
+	m.fieldGens = fieldGens
+	m.beforeGen = append(m.beforeGen, b.beforeGen...)
+	m.afterGen = append(m.afterGen, b.afterGen...)
+	m.validate = append(m.validate, b.validate...)
+	m.collect = append(m.collect, b.collect...)
+	m.preserve = append(m.preserve[:len(m.preserve):len(m.preserve)], b.preserve...)
+	for name, trait := range b.traits {
+		m.traits[name] = trait
+	}
+	if b.rand != nil {
+		m.rand = b.rand
+	}
+	if b.maxDepth != 0 {
+		m.maxDepth = b.maxDepth
+	}
+	if b.registry != nil {
+		m.registry = b.registry
+	}
+	m.strictNil = f.strictNil || b.strictNil
+	m.bindRegistry()
+	return m
+}
+
+// BeforeCreate registers a hook that is called before any field generator
+// on every instance including the ones created in recursive calls. Hooks are called in order of registration.
+// The error returned by hook aborts generation of the instance and is returned by SetFields and Create.
+func (f *Factory) BeforeCreate(hook HookFunc) *Factory {
+	// force reallocation so the hook does not leak into derived factories
+	f.beforeGen = append(f.beforeGen[:len(f.beforeGen):len(f.beforeGen)], hook)
+	return f
+}
+
+// AfterCreate registers a hook that is called after all the field generators
+// so it can post-process the fully populated instance. Hooks are called in order of registration.
+// The error returned by hook is returned by SetFields and Create.
+func (f *Factory) AfterCreate(hook HookFunc) *Factory {
+	// force reallocation so the hook does not leak into derived factories
+	f.afterGen = append(f.afterGen[:len(f.afterGen):len(f.afterGen)], hook)
+	return f
 }
 
 func (f *Factory) new() reflect.Value {
 	return reflect.New(f.typ)
 }
 
-// SetFields fills in the struct instance fields
+// SetFields fills in the struct instance fields.
+// Called recursively from generator it inherits the context of the instance being created.
 func (f *Factory) SetFields(i interface{}, fieldGenFuncs ...FieldGenFunc) error {
+	ctx := f.context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return f.SetFieldsCtx(ctx, i, fieldGenFuncs...)
+}
+
+// SetFieldsCtx fills in the struct instance fields passing the context to generators
+func (f *Factory) SetFieldsCtx(c context.Context, i interface{}, fieldGenFuncs ...FieldGenFunc) error {
 	if len(fieldGenFuncs) > 0 {
-		return f.Derive(fieldGenFuncs...).SetFields(i)
+		d, err := f.derive(fieldGenFuncs...)
+		if err != nil {
+			return err
+		}
+		return d.SetFieldsCtx(c, i)
 	}
 
-	// create execution context
-	ctx := Ctx{Instance: i, Factory: f.dive()}
+	if err := c.Err(); err != nil {
+		return err
+	}
+
+	if f.tooDeep() {
+		return nil
+	}
+
+	// create execution context, the factory of nested calls is bound to it
+	// on demand so the static values are assigned without copying the factory
+	ctx := Ctx{Instance: i, Rand: f.randSource(), Context: c, Index: f.index, Parent: f.parent}
+	if len(f.beforeGen) > 0 {
+		ctx.Factory = f.self(c, i)
+	}
+
+	for _, hook := range f.beforeGen {
+		if err := hook(ctx); err != nil {
+			return err
+		}
+	}
 
 	elem := reflect.ValueOf(i).Elem()
+	if elem.Kind() == reflect.Map && elem.IsNil() {
+		elem.Set(reflect.MakeMap(elem.Type()))
+	}
+
+	// the fields filled in by registered factories go first like proto fields
+	fieldGens := f.fieldGens
+	if f.regGens != nil {
+		fieldGens = f.regGens
+	}
+
+	// the fields to preserve are checked before any generator runs
+	preserved := f.preservedFields(i, fieldGens)
+
+	for _, fg := range fieldGens {
+		if preserved[fg.Name] {
+			continue
+		}
+
+		// fast path of static values adapted to the field type on factory creation
+		if fg.static.IsValid() {
+			if f.strictNil && isNil(fg.raw) && !nilable(fg.Type) {
+				return fieldError(fg.Name, fmt.Errorf("cannot assign nil to %s", fg.Type))
+			}
+			setField(elem, fg, fg.static)
+			continue
+		}
 
-	for _, fg := range f.fieldGens {
 		// bind field name o context
 		ctx.Field = fg.Name
+		if ctx.Factory == nil {
+			ctx.Factory = f.self(c, i)
+		}
 
 		// generate field value
 		val, err := fg.gen(ctx)
 		if err != nil {
-			return err
+			return fieldError(fg.Name, err)
+		}
+
+		if val == Unset {
+			continue
 		}
 
-		valueof := reflect.ValueOf(val)
+		if f.strictNil && isNil(val) && !nilable(fg.Type) {
+			return fieldError(fg.Name, fmt.Errorf("cannot assign nil to %s", fg.Type))
+		}
 
-		switch valueof.Kind() {
-		case reflect.Ptr:
-			// deref pointer if field is not a pointer kind
-			if fg.Type.Kind() != reflect.Ptr {
-				valueof = valueof.Elem()
-			}
-		case reflect.Invalid:
-			// for example we are here if fg.generator(ctx) returns (nil, nil)
-			valueof = reflect.Zero(fg.Type)
+		// adapt generated value to the field type
+		valueof, err := valueFor(val, fg.Type)
+		if err != nil {
+			return fieldError(fg.Name, err)
+		}
+		setField(elem, fg, valueof)
+	}
+
+	// no field is being generated in hooks
+	ctx.Field = ""
+	if ctx.Factory == nil && len(f.afterGen) > 0 {
+		ctx.Factory = f.self(c, i)
+	}
+	for _, hook := range f.afterGen {
+		if err := hook(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, validate := range f.validate {
+		if err := validate(i); err != nil {
+			return err
 		}
+	}
 
-		// find field by index
-		field := elem.FieldByIndex(fg.Index)
-		// and assign value to field
-		field.Set(valueof)
+	for _, collect := range f.collect {
+		collect(i)
 	}
 	return nil
 }
 
+// setField assigns the value to the field of struct or the entry of map elem
+func setField(elem reflect.Value, fg fieldWithGen, valueof reflect.Value) {
+	if elem.Kind() == reflect.Map {
+		elem.SetMapIndex(reflect.ValueOf(fg.Name).Convert(elem.Type().Key()), valueof)
+		return
+	}
+
+	// find field by index, it's always found as the path is checked on factory creation
+	field, _ := fieldByIndex(elem, fg.Index)
+	// and assign value to field
+	field.Set(valueof)
+}
+
+// preservedFields returns the names of fields of instance i to leave as they are,
+// that are the fields matching Preserve filters and already set to non-zero value
+func (f *Factory) preservedFields(i interface{}, fieldGens []fieldWithGen) map[string]bool {
+	if len(f.preserve) == 0 {
+		return nil
+	}
+
+	elem := reflect.ValueOf(i).Elem()
+	preserved := map[string]bool{}
+	for _, fg := range fieldGens {
+		if !matchAny(fg.Name, f.preserve) {
+			continue
+		}
+		if val, ok := currentValue(elem, fg); ok && !val.IsZero() {
+			preserved[fg.Name] = true
+		}
+	}
+	return preserved
+}
+
+// currentValue returns the current value of the field of struct or the entry of map elem
+// found by index path or key. It returns false if there is a nil pointer to struct on the path
+// or the map has no such key.
+func currentValue(elem reflect.Value, fg fieldWithGen) (reflect.Value, bool) {
+	if elem.Kind() == reflect.Map {
+		val := elem.MapIndex(reflect.ValueOf(fg.Name).Convert(elem.Type().Key()))
+		if val.Kind() == reflect.Interface {
+			val = val.Elem()
+		}
+		return val, val.IsValid()
+	}
+	val, err := elem.FieldByIndexErr(fg.Index)
+	return val, err == nil
+}
+
+// matchAny checks if any of the filters matches the named field
+func matchAny(name string, filters []func(string) bool) bool {
+	for _, match := range filters {
+		if match(name) {
+			return true
+		}
+	}
+	return false
+}
+
 // MustSetFields calls SetFields and panics on error
 func (f *Factory) MustSetFields(i interface{}, fieldGenFuncs ...FieldGenFunc) {
 	if err := f.SetFields(i, fieldGenFuncs...); err != nil {
@@ -138,8 +592,12 @@ func (f *Factory) MustSetFields(i interface{}, fieldGenFuncs ...FieldGenFunc) {
 	}
 }
 
-// Create makes a new instance
+// Create makes a new instance. It returns nil pointer if max call depth is reached.
 func (f *Factory) Create(fieldGenFuncs ...FieldGenFunc) (interface{}, error) {
+	if f.tooDeep() {
+		return reflect.Zero(reflect.PtrTo(f.typ)).Interface(), nil
+	}
+
 	// allocate a new instance
 	instance := f.new()
 	if err := f.SetFields(instance.Interface(), fieldGenFuncs...); err != nil {
@@ -148,6 +606,20 @@ func (f *Factory) Create(fieldGenFuncs ...FieldGenFunc) (interface{}, error) {
 	return instance.Interface(), nil
 }
 
+// CreateCtx makes a new instance passing the context to generators
+func (f *Factory) CreateCtx(c context.Context, fieldGenFuncs ...FieldGenFunc) (interface{}, error) {
+	if f.tooDeep() {
+		return reflect.Zero(reflect.PtrTo(f.typ)).Interface(), nil
+	}
+
+	// allocate a new instance
+	instance := f.new()
+	if err := f.SetFieldsCtx(c, instance.Interface(), fieldGenFuncs...); err != nil {
+		return nil, err
+	}
+	return instance.Interface(), nil
+}
+
 // MustCreate creates or panics
 func (f *Factory) MustCreate(fieldGenFuncs ...FieldGenFunc) interface{} {
 	i, err := f.Create(fieldGenFuncs...)
@@ -157,54 +629,621 @@ func (f *Factory) MustCreate(fieldGenFuncs ...FieldGenFunc) interface{} {
 	return i
 }
 
+// CreateSafe is like Create but recovers from panics raised by misconfigured generators,
+// like the ones that refer to unexported fields, and returns them as errors.
+// Runtime errors like nil pointer dereference are not factory-originated and panic further.
+func (f *Factory) CreateSafe(fieldGenFuncs ...FieldGenFunc) (i interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(runtime.Error); ok {
+				panic(r)
+			}
+			if e, ok := r.(error); ok {
+				err = fmt.Errorf("%s factory failed: %w", f.typ, e)
+				return
+			}
+			err = fmt.Errorf("%s factory failed: %v", f.typ, r)
+		}
+	}()
+	return f.Create(fieldGenFuncs...)
+}
+
+// CreateN makes n new instances. Field generator overrides, if any, are applied
+// to every instance of the batch. It returns an error if n is negative.
+func (f *Factory) CreateN(n int, fieldGenFuncs ...FieldGenFunc) ([]interface{}, error) {
+	if err := checkCount(n); err != nil {
+		return nil, err
+	}
+	if len(fieldGenFuncs) > 0 {
+		// derive once so stateful generators keep advancing across the batch
+		d, err := f.derive(fieldGenFuncs...)
+		if err != nil {
+			return nil, err
+		}
+		return d.CreateN(n)
+	}
+
+	instances := make([]interface{}, n)
+	for i := range instances {
+		instance, err := f.at(i).Create()
+		if err != nil {
+			return nil, err
+		}
+		instances[i] = instance
+	}
+	return instances, nil
+}
+
+// CreateBatch makes n new instances applying the field generator overrides returned by perIndex(i)
+// to the instance with index i, for example to make the first 3 users admins. The generators of
+// the factory keep advancing across the batch. On the first failure it returns the instances
+// created so far along with the error. It returns an error if n is negative.
+func (f *Factory) CreateBatch(n int, perIndex func(i int) []FieldGenFunc) ([]interface{}, error) {
+	if err := checkCount(n); err != nil {
+		return nil, err
+	}
+	instances := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		instance, err := f.at(i).Create(perIndex(i)...)
+		if err != nil {
+			return instances, err
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// checkCount returns an error if the number of instances to create is negative
+func checkCount(n int) error {
+	if n < 0 {
+		return fmt.Errorf("expect non-negative number of instances but was: %d", n)
+	}
+	return nil
+}
+
+// at returns a copy of factory creating the instance with index i in a batch
+func (f *Factory) at(i int) *Factory {
+	d := *f
+	d.index = i
+	return &d
+}
+
+// MustCreateN creates n instances or panics
+func (f *Factory) MustCreateN(n int, fieldGenFuncs ...FieldGenFunc) []interface{} {
+	instances, err := f.CreateN(n, fieldGenFuncs...)
+	if err != nil {
+		panic(err)
+	}
+	return instances
+}
+
+// FillSlice sets dst, that must be a pointer to slice of factory type values or pointers
+// like *[]User or *[]*User, to a slice of n new instances.
+func (f *Factory) FillSlice(dst interface{}, n int, fieldGenFuncs ...FieldGenFunc) error {
+	ptr := reflect.ValueOf(dst)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("expect pointer to slice but was: %T", dst)
+	}
+
+	typ := ptr.Elem().Type()
+	elemType := typ.Elem()
+	if elemType != f.typ && elemType != reflect.PtrTo(f.typ) {
+		return fmt.Errorf("expect pointer to slice of %s but was: %T", f.typ, dst)
+	}
+
+	instances, err := f.CreateN(n, fieldGenFuncs...)
+	if err != nil {
+		return err
+	}
+
+	slice := reflect.MakeSlice(typ, n, n)
+	for i, instance := range instances {
+		val := reflect.ValueOf(instance)
+		if elemType.Kind() != reflect.Ptr {
+			val = val.Elem()
+		}
+		slice.Index(i).Set(val)
+	}
+	ptr.Elem().Set(slice)
+	return nil
+}
+
 // WithGen returns a function that generates an array of field generators,
 // each of which has embedded check for field is present in the object being created and can be set.
+// The field can be addressed by dotted path like "Address.City" to set the field of nested struct.
 func WithGen(g GeneratorFunc, fields ...string) FieldGenFunc {
 	return func(sample reflect.Value) []fieldWithGen {
 		gens := []fieldWithGen{}
-		elem := sample.Elem()
-		typ := elem.Type()
 		for _, fieldName := range fields {
-			sField, ok := typ.FieldByName(fieldName)
-			if !ok {
-				panic(fmt.Errorf("field %q not found in %s", fieldName, typ.Name()))
+			sField, err := resolveField(sample, fieldName)
+			if err != nil {
+				panic(err)
+			}
+			gens = append(gens, fieldWithGen{StructField: &sField, gen: g})
+		}
+		return gens
+	}
+}
+
+// WithGenIndex is like WithGen but binds the generator to the field addressed by the index path,
+// like reflect.StructField.Index, skipping the lookup of fields by name. The field is named after
+// the names of fields along the path, like "Address.City". It panics if the field is not found or can not be set.
+func WithGenIndex(g GeneratorFunc, index []int) FieldGenFunc {
+	return func(sample reflect.Value) []fieldWithGen {
+		sField, err := resolveFieldIndex(sample, index)
+		if err != nil {
+			panic(err)
+		}
+		return []fieldWithGen{{StructField: &sField, gen: g}}
+	}
+}
+
+// WithGenE is like WithGen but instead of panic on the field that is not found or can not be set
+// it makes the factory constructor NewFactoryE, as well as Create and SetFields, return an error.
+func WithGenE(g GeneratorFunc, fields ...string) FieldGenFunc {
+	return func(sample reflect.Value) []fieldWithGen {
+		gens := []fieldWithGen{}
+		for _, fieldName := range fields {
+			sField, err := resolveField(sample, fieldName)
+			if err != nil {
+				return []fieldWithGen{{err: err}}
+			}
+			gens = append(gens, fieldWithGen{StructField: &sField, gen: g})
+		}
+		return gens
+	}
+}
+
+// DependsOn makes the field generators run after the generators of listed fields
+// regardless of the order of registration.
+func (fgf FieldGenFunc) DependsOn(fields ...string) FieldGenFunc {
+	return func(sample reflect.Value) []fieldWithGen {
+		fieldGens := fgf(sample)
+		for i := range fieldGens {
+			fieldGens[i].deps = append(fieldGens[i].deps, fields...)
+		}
+		return fieldGens
+	}
+}
+
+// checkField panics if check returns an error on the type of any field the generators are bound to
+func (fgf FieldGenFunc) checkField(check func(typ reflect.Type) error) FieldGenFunc {
+	return func(sample reflect.Value) []fieldWithGen {
+		fieldGens := fgf(sample)
+		for _, fg := range fieldGens {
+			if err := check(fg.Type); err != nil {
+				panic(fmt.Errorf("field %q: %v", fg.Name, err))
+			}
+		}
+		return fieldGens
+	}
+}
+
+// staticValue marks the field generators as yielding the same value val on every call, so the value
+// is adapted to the field type once and assigned without calling the generator. The values assigned
+// by pointer, like pointers or the values allocated for pointer fields, are adapted on every call as usual.
+// Like with the generator, the values holding references, like slices and maps, are assigned as is,
+// so all the instances share them. Unlike proto values they are not copied.
+func (fgf FieldGenFunc) staticValue(val interface{}) FieldGenFunc {
+	return func(sample reflect.Value) []fieldWithGen {
+		fieldGens := fgf(sample)
+		if val == Unset || val != nil && reflect.TypeOf(val).Kind() == reflect.Ptr {
+			return fieldGens
+		}
+		for i := range fieldGens {
+			fg := &fieldGens[i]
+			if val != nil && fg.Type.Kind() == reflect.Ptr {
+				continue
+			}
+			if valueof, err := valueFor(val, fg.Type); err == nil {
+				fg.static, fg.raw = valueof, val
 			}
+		}
+		return fieldGens
+	}
+}
 
-			// check that field exists in generated model
-			field := elem.FieldByIndex(sField.Index)
+// kinded labels the field generators with kind of generator
+func (fgf FieldGenFunc) kinded(kind genKind) FieldGenFunc {
+	return func(sample reflect.Value) []fieldWithGen {
+		fieldGens := fgf(sample)
+		for i := range fieldGens {
+			fieldGens[i].kind = kind
+			fieldGens[i].fromProto = kind == kindProto
+		}
+		return fieldGens
+	}
+}
 
-			if !field.IsValid() {
-				panic(fmt.Errorf("field %q is not valid in %s", fieldName, typ.Name()))
+// sortFieldGens orders field generators so that each one runs after the generators of
+// the fields it depends on and after the preceding generators of the same field.
+// Otherwise the order of registration is kept. It returns an error on dependency cycle
+// and on dependency on the field that is not found in the type of factory.
+func (f *Factory) sortFieldGens(fieldGens []fieldWithGen) ([]fieldWithGen, error) {
+	hasDeps := false
+	for _, fg := range fieldGens {
+		hasDeps = hasDeps || len(fg.deps) > 0
+	}
+	if !hasDeps {
+		return fieldGens, nil
+	}
+
+	// the dependencies may have no generators, for example if they are omitted,
+	// but they must name the fields of the type to catch typos
+	sample := f.new()
+	for _, fg := range fieldGens {
+		for _, dep := range fg.deps {
+			if _, err := resolveField(sample, dep); err != nil {
+				return nil, fmt.Errorf("dependency of field %q: %w", fg.Name, err)
 			}
+		}
+	}
+
+	// number of not yet sorted generators per field
+	pending := make(map[string]int, len(fieldGens))
+	for _, fg := range fieldGens {
+		pending[fg.Name]++
+	}
 
-			// and can be set
-			if !field.CanSet() {
-				panic(fmt.Errorf("field %q can not be set in %s", fieldName, typ.Name()))
+	done := make([]bool, len(fieldGens))
+	sorted := make([]fieldWithGen, 0, len(fieldGens))
+
+	// ready checks if generator can be taken: it's the first pending one
+	// for its field and all its dependencies are sorted
+	ready := func(i int) bool {
+		for j := 0; j < i; j++ {
+			if !done[j] && fieldGens[j].Name == fieldGens[i].Name {
+				return false
+			}
+		}
+		for _, dep := range fieldGens[i].deps {
+			if pending[dep] > 0 {
+				return false
 			}
+		}
+		return true
+	}
 
-			gens = append(gens, fieldWithGen{&sField, g})
+	for len(sorted) < len(fieldGens) {
+		next := -1
+		for i := range fieldGens {
+			if !done[i] && ready(i) {
+				next = i
+				break
+			}
 		}
-		return gens
+
+		if next < 0 {
+			names := []string{}
+			for i, fg := range fieldGens {
+				if !done[i] && len(fg.deps) > 0 {
+					names = append(names, fmt.Sprintf("%q", fg.Name))
+				}
+			}
+			// list the fields sorted so the message is the same whatever order they are registered in
+			sort.Strings(names)
+			return nil, fmt.Errorf("dependency cycle among fields %s", strings.Join(names, ", "))
+		}
+
+		done[next] = true
+		pending[fieldGens[next].Name]--
+		sorted = append(sorted, fieldGens[next])
 	}
+	return sorted, nil
 }
 
-// check if value is zero value
-func isZero(val reflect.Value) bool {
-	switch val.Kind() {
-	case reflect.Slice, reflect.Ptr, reflect.Map:
-		return val.IsNil()
+// fieldKey identifies the field path resolved in the type
+type fieldKey struct {
+	typ  reflect.Type
+	path string
+}
+
+// fieldCache keeps the struct fields resolved by resolveField, so derived factories and generators
+// of the same fields don't look them up by name again. It's global as the lookup depends on the type
+// only and types never change. It only grows with the pairs of struct type and field path found in
+// the factories of the program, as neither the errors nor the keys of map instances are cached.
+var fieldCache sync.Map // fieldKey -> reflect.StructField
+
+// resolveField is like lookupField but caches the struct fields found per type and path.
+// The struct field returned shares the index path with cache, so it must not be modified.
+func resolveField(sample reflect.Value, path string) (reflect.StructField, error) {
+	if sample.Type().Elem().Kind() == reflect.Map {
+		return lookupField(sample, path)
+	}
+
+	key := fieldKey{typ: sample.Type(), path: path}
+	if cached, ok := fieldCache.Load(key); ok {
+		return cached.(reflect.StructField), nil
+	}
+
+	sField, err := lookupField(sample, path)
+	if err == nil {
+		fieldCache.Store(key, sField)
+	}
+	return sField, err
+}
+
+// lookupField walks the dotted field path in a sample instance and returns the struct field
+// named after the full path with the index path from the instance root. Nil pointers to
+// nested structs met along the path are allocated in the sample.
+func lookupField(sample reflect.Value, path string) (reflect.StructField, error) {
+	if typ := sample.Type().Elem(); typ.Kind() == reflect.Map {
+		return mapKeyField(typ, path)
+	}
+
+	var sField reflect.StructField
+
+	val := sample.Elem()
+	typ := val.Type()
+	index := []int{}
+
+	for _, name := range strings.Split(path, ".") {
+		if val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				val.Set(reflect.New(val.Type().Elem()))
+			}
+			val = val.Elem()
+		}
+
+		if val.Kind() != reflect.Struct {
+			return sField, fmt.Errorf("field %q not found in %s", path, typ.Name())
+		}
+
+		var ok bool
+		if sField, ok = val.Type().FieldByName(name); !ok {
+			return sField, fmt.Errorf("field %q not found in %s", path, typ.Name())
+		}
+
+		// check that field exists in generated model
+		field, ok := fieldByIndex(val, sField.Index)
+		if !ok {
+			return sField, fmt.Errorf("field %q can not be set in %s", path, typ.Name())
+		}
+
+		if !field.IsValid() {
+			return sField, fmt.Errorf("field %q is not valid in %s", path, typ.Name())
+		}
+
+		// and can be set
+		if !field.CanSet() {
+			return sField, fmt.Errorf("field %q can not be set in %s", path, typ.Name())
+		}
+
+		index = append(index, sField.Index...)
+		val = field
+	}
+
+	sField.Name = path
+	sField.Index = index
+	return sField, nil
+}
+
+// mapKeyField makes a field standing for the key of map with string keys. The key is taken
+// as is, so dotted path does not address nested values.
+func mapKeyField(typ reflect.Type, key string) (reflect.StructField, error) {
+	if typ.Key().Kind() != reflect.String {
+		return reflect.StructField{}, fmt.Errorf("can not set key %q of %s, expect string keys", key, typ)
+	}
+	return reflect.StructField{Name: key, Type: typ.Elem()}, nil
+}
+
+// valueFor adapts the generated value to be assigned to a field of type typ.
+// It returns an error if the value can not be assigned.
+func valueFor(val interface{}, typ reflect.Type) (reflect.Value, error) {
+	valueof := reflect.ValueOf(val)
+
+	if typ.Kind() == reflect.Interface && valueof.IsValid() {
+		return interfaceValueFor(valueof, typ)
+	}
+
+	switch valueof.Kind() {
+	case reflect.Ptr:
+		// deref pointer if field is not a pointer kind
+		if typ.Kind() != reflect.Ptr {
+			if valueof.IsNil() {
+				return reflect.Zero(typ), nil
+			}
+			valueof = valueof.Elem()
+		}
+	case reflect.Struct:
+		// allocate pointer if field is a pointer to struct of the value type
+		if typ.Kind() == reflect.Ptr && valueof.Type() == typ.Elem() {
+			ptr := reflect.New(typ.Elem())
+			ptr.Elem().Set(valueof)
+			return ptr, nil
+		}
+	case reflect.Invalid:
+		// for example we are here if generator returns (nil, nil)
+		return reflect.Zero(typ), nil
+	}
+
+	// allocate pointer if field is a pointer to the type the value can be assigned or converted to
+	if typ.Kind() == reflect.Ptr && valueof.Kind() != reflect.Ptr {
+		if elem := typ.Elem(); valueof.Type().AssignableTo(elem) || convertible(valueof.Type(), elem) {
+			converted, err := convertValue(valueof, elem)
+			if err != nil {
+				return valueof, err
+			}
+			ptr := reflect.New(elem)
+			ptr.Elem().Set(converted)
+			return ptr, nil
+		}
+	}
+
+	// convert value if field type is different but convertible
+	if !valueof.Type().AssignableTo(typ) {
+		if !convertible(valueof.Type(), typ) {
+			return valueof, fmt.Errorf("cannot assign %s to %s", reflect.TypeOf(val), typ)
+		}
+		return convertValue(valueof, typ)
+	}
+	return valueof, nil
+}
+
+// convertValue converts the value to type typ. It returns an error if a number doesn't fit into the type,
+// like 300 into int8, -1 into uint or 1.5 into int.
+func convertValue(valueof reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	converted := valueof.Convert(typ)
+	if !lossless(valueof, converted) {
+		return valueof, fmt.Errorf("cannot convert %v of %s to %s without loss", valueof, valueof.Type(), typ)
+	}
+	return converted, nil
+}
+
+// lossless checks if the number keeps its value after conversion. Floating point numbers may be rounded
+// to the precision of the type, but integers must keep all the digits and the sign. Other kinds are always lossless.
+func lossless(from, to reflect.Value) bool {
+	switch to.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch from.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return from.Int() == to.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return from.Uint() <= math.MaxInt64 && int64(from.Uint()) == to.Int()
+		case reflect.Float32, reflect.Float64:
+			f := from.Float()
+			return f >= math.MinInt64 && f < -math.MinInt64 && float64(to.Int()) == f
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		switch from.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return from.Int() >= 0 && uint64(from.Int()) == to.Uint()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return from.Uint() == to.Uint()
+		case reflect.Float32, reflect.Float64:
+			f := from.Float()
+			return f >= 0 && f < math.Exp2(64) && float64(to.Uint()) == f
+		}
+	case reflect.Float32, reflect.Float64:
+		switch from.Kind() {
+		case reflect.Float32, reflect.Float64:
+			// overflow turns finite numbers to infinity
+			return !math.IsInf(to.Float(), 0) || math.IsInf(from.Float(), 0)
+		}
+	}
+	return true
+}
+
+// isNil checks if the generated value is nil or nil pointer
+func isNil(val interface{}) bool {
+	if val == nil {
+		return true
+	}
+	v := reflect.ValueOf(val)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// nilable checks if the value of type can be nil
+func nilable(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Chan, reflect.Func:
+		return true
 	default:
-		// otherwise allocate zero value
-		zero := reflect.Zero(val.Type())
-		// and compare
-		return val.Interface() == zero.Interface()
+		return false
 	}
 }
 
+// interfaceValueFor adapts the generated value to be assigned to a field of interface type.
+// The value is assigned as is, so pointers created by sub-factories are not dereferenced.
+func interfaceValueFor(valueof reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	if vtyp := valueof.Type(); !vtyp.Implements(typ) {
+		return valueof, fmt.Errorf("%s does not implement %s", vtyp, typ)
+	}
+	return valueof, nil
+}
+
+// convertible checks if value of type from can be converted to type to. Whether a number fits
+// into the type is checked by convertValue.
+func convertible(from, to reflect.Type) bool {
+	if !from.ConvertibleTo(to) {
+		return false
+	}
+
+	switch from.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		// integer to string conversion yields a rune, not a number representation
+		return to.Kind() != reflect.String
+	case reflect.Slice:
+		// slice to array (pointer) conversion panics if the slice is too short
+		return to.Kind() != reflect.Array && to.Kind() != reflect.Ptr
+	default:
+		return true
+	}
+}
+
+// resolveFieldIndex walks the index path in a sample instance and returns the struct field named
+// after the names of fields along the path. Nil pointers to nested structs are allocated in the sample.
+func resolveFieldIndex(sample reflect.Value, index []int) (reflect.StructField, error) {
+	var sField reflect.StructField
+
+	val := sample.Elem()
+	typ := val.Type()
+	names := make([]string, 0, len(index))
+
+	if len(index) == 0 {
+		return sField, fmt.Errorf("field index %v not found in %s", index, typ.Name())
+	}
+
+	for _, x := range index {
+		if val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				val.Set(reflect.New(val.Type().Elem()))
+			}
+			val = val.Elem()
+		}
+
+		if val.Kind() != reflect.Struct || x < 0 || x >= val.NumField() {
+			return sField, fmt.Errorf("field index %v not found in %s", index, typ.Name())
+		}
+
+		sField = val.Type().Field(x)
+		names = append(names, sField.Name)
+		field := val.Field(x)
+		if !field.CanSet() {
+			return sField, fmt.Errorf("field %q can not be set in %s", strings.Join(names, "."), typ.Name())
+		}
+		val = field
+	}
+
+	sField.Name = strings.Join(names, ".")
+	sField.Index = append([]int(nil), index...)
+	return sField, nil
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex but allocates nil pointers to nested
+// and embedded structs met along the index path. It returns false if the pointer to
+// embedded struct is unexported and can't be allocated.
+func fieldByIndex(val reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				if !val.CanSet() {
+					return val, false
+				}
+				val.Set(reflect.New(val.Type().Elem()))
+			}
+			val = val.Elem()
+		}
+		val = val.Field(x)
+	}
+	return val, true
+}
+
+// RecurseProto defines whether partially set struct fields of proto object are decomposed
+// into field generators of nested fields (like "Address.City") that run after other generators,
+// so that sub-factory bound to the struct field still fills in the rest of nested fields.
+// Set it to false to copy such struct fields as a whole.
+var RecurseProto = true
+
 // protoGens takes a proto object and decomposes it into slice of field generators
-// for each proto object field that has non-zero value.
-func protoGens(proto interface{}) (fieldGenFuncs []FieldGenFunc) {
+// for each proto object field that has non-zero value. The generators of nested fields
+// of partially set structs are returned separately.
+func protoGens(proto interface{}) (fieldGenFuncs, nestedGenFuncs []FieldGenFunc) {
 	typ := reflect.TypeOf(proto)
+	if typ.Kind() == reflect.Map {
+		return mapProtoGens(reflect.ValueOf(proto)), nil
+	}
 
 	// if proto object is non-zero type,
 	// walk object fields and create field generator for each field with non-zero value
@@ -218,37 +1257,311 @@ func protoGens(proto interface{}) (fieldGenFuncs []FieldGenFunc) {
 			continue
 		}
 
-		if fVal := val.Field(i); !isZero(fVal) {
-			iVal := fVal.Interface()
-			fGen := Use(iVal).For(sField.Name)
-			if fieldGenFuncs != nil {
-				fieldGenFuncs = append(fieldGenFuncs, fGen)
-			} else {
-				fieldGenFuncs = []FieldGenFunc{fGen}
+		if fVal := val.Field(i); !fVal.IsZero() {
+			if RecurseProto && isPartialStruct(fVal) {
+				nestedGenFuncs = append(nestedGenFuncs, nestedProtoGens(fVal, sField.Name)...)
+				continue
 			}
+			fieldGenFuncs = append(fieldGenFuncs, protoGen(fVal, sField.Name))
+		}
+	}
+	return
+}
+
+// mapProtoGens makes field generators for all the entries of proto map
+// including the ones with zero values, sorted by key
+func mapProtoGens(val reflect.Value) (fieldGenFuncs []FieldGenFunc) {
+	keys := val.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, key := range keys {
+		entry := val.MapIndex(key)
+		if entry.Kind() == reflect.Interface && !entry.IsNil() {
+			// copy the value behind interface if it's a slice or map
+			entry = entry.Elem()
 		}
+		fieldGenFuncs = append(fieldGenFuncs, protoGen(entry, key.String()))
 	}
 	return
 }
 
-// NewFactory is factory constructor
+// nestedProtoGens makes field generators for non-zero fields of struct value
+// naming them with dotted path starting from path.
+func nestedProtoGens(val reflect.Value, path string) (fieldGenFuncs []FieldGenFunc) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		sField := typ.Field(i)
+		if sField.PkgPath != "" {
+			continue
+		}
+
+		if fVal := val.Field(i); !fVal.IsZero() {
+			name := path + "." + sField.Name
+			if isPartialStruct(fVal) {
+				fieldGenFuncs = append(fieldGenFuncs, nestedProtoGens(fVal, name)...)
+				continue
+			}
+			fieldGenFuncs = append(fieldGenFuncs, protoGen(fVal, name))
+		}
+	}
+	return
+}
+
+// protoGen makes generator of the field name with proto value val
+func protoGen(val reflect.Value, name string) FieldGenFunc {
+	fgf := WithGen(protoValue(val), name).kinded(kindProto)
+	if hasReferences(val.Type()) {
+		return fgf
+	}
+	return fgf.staticValue(val.Interface())
+}
+
+// protoValue returns generator of proto field value. Slices, maps and arrays of them
+// are copied on every call, so created instances don't share backing arrays and maps.
+// The structs behind pointers are copied too, so generators of nested fields like
+// "Address.City" don't write through the pointer to the other instances and the proto.
+func protoValue(val reflect.Value) GeneratorFunc {
+	if val.Kind() == reflect.Ptr && val.Type().Elem().Kind() == reflect.Struct && !val.IsNil() {
+		return func(Ctx) (interface{}, error) {
+			ptr := reflect.New(val.Type().Elem())
+			ptr.Elem().Set(val.Elem())
+			return ptr.Interface(), nil
+		}
+	}
+	if !hasReferences(val.Type()) {
+		return adaptValue(val.Interface())
+	}
+	return func(Ctx) (interface{}, error) {
+		return deepCopy(val).Interface(), nil
+	}
+}
+
+// hasReferences checks if values of type share slices or maps when copied
+func hasReferences(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Map:
+		return true
+	case reflect.Array:
+		return hasReferences(typ.Elem())
+	default:
+		return false
+	}
+}
+
+// deepCopy copies slices and maps recursively. Other values are copied as is,
+// so pointers and structs holding slices or maps still share the data they refer to.
+func deepCopy(val reflect.Value) reflect.Value {
+	switch val.Kind() {
+	case reflect.Slice:
+		if val.IsNil() {
+			return val
+		}
+		c := reflect.MakeSlice(val.Type(), val.Len(), val.Len())
+		for i := 0; i < val.Len(); i++ {
+			c.Index(i).Set(deepCopy(val.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(val.Type()).Elem()
+		for i := 0; i < val.Len(); i++ {
+			c.Index(i).Set(deepCopy(val.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if val.IsNil() {
+			return val
+		}
+		c := reflect.MakeMapWithSize(val.Type(), val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+	default:
+		return val
+	}
+}
+
+// isPartialStruct checks if value is a struct that can be decomposed into field generators
+// without loosing information, i.e. all its non-zero fields are exported.
+// For example time.Time is not.
+func isPartialStruct(val reflect.Value) bool {
+	if val.Kind() != reflect.Struct {
+		return false
+	}
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).PkgPath != "" && !val.Field(i).IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// Prototype is a proto object with the list of fields to take from it
+// regardless of their values. Use Proto to make it.
+type Prototype struct {
+	obj    interface{}
+	fields []string
+}
+
+// Proto wraps the proto object to pass to the factory constructor so that listed fields
+// are taken from it even if they have zero values like false, 0 or "". Otherwise zero value
+// fields are considered unset. The fields can be addressed by dotted path like "Address.City".
+func Proto(obj interface{}, fields ...string) Prototype {
+	return Prototype{obj: obj, fields: fields}
+}
+
+// unwrapProto returns proto object and the fields to take from it regardless of their values
+func unwrapProto(proto interface{}) (interface{}, []string) {
+	if p, ok := proto.(Prototype); ok {
+		return p.obj, p.fields
+	}
+	return proto, nil
+}
+
+// pinnedProtoGens makes field generators for the listed proto fields that have zero values,
+// the generators of non-zero fields are made by protoGens.
+func pinnedProtoGens(proto interface{}, fields []string) ([]FieldGenFunc, error) {
+	// copy proto to addressable value to walk its fields
+	val := reflect.New(reflect.TypeOf(proto))
+	val.Elem().Set(reflect.ValueOf(proto))
+
+	fieldGenFuncs := []FieldGenFunc{}
+	for _, name := range fields {
+		sField, err := resolveField(val, name)
+		if err != nil {
+			return nil, err
+		}
+		if fVal, _ := fieldByIndex(val.Elem(), sField.Index); fVal.IsZero() {
+			fieldGenFuncs = append(fieldGenFuncs, protoGen(fVal, name))
+		}
+	}
+	return fieldGenFuncs, nil
+}
+
+// NewFactory is factory constructor. The proto object can be wrapped with Proto
+// to take its zero value fields as well.
 func NewFactory(proto interface{}, fieldGenFuncs ...FieldGenFunc) *Factory {
+	f, err := NewFactoryE(proto, fieldGenFuncs...)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// NewFactoryE is factory constructor that returns an error instead of panic
+// on field generators made with WithGenE that refer to not existing or not settable fields.
+func NewFactoryE(proto interface{}, fieldGenFuncs ...FieldGenFunc) (*Factory, error) {
+	proto, pinned := unwrapProto(proto)
 	typ := reflect.TypeOf(proto)
 
-	if protogens := protoGens(proto); len(protogens) > 0 {
+	protogens, nestedgens := protoGens(proto)
+	pinnedgens, err := pinnedProtoGens(proto, pinned)
+	if err != nil {
+		return nil, err
+	}
+	// pinned fields are set like nested ones after sub-factories of parent fields
+	nestedgens = append(nestedgens, pinnedgens...)
+	if len(protogens) > 0 {
 		// prepend field generators with proto generators if there are some
 		fieldGenFuncs = append(protogens, fieldGenFuncs...)
 	}
 
+	f := &Factory{typ: typ, resets: &resetSet{}}
+
 	// sample is used to validate during the factory construction process that all
 	// provided fields exist in a given interface and can be set.
-	sample := reflect.New(typ)
+	sample := f.new()
+	// filters have nothing to filter out in a new factory
+	fieldGens, _, preserve, err := f.makeFieldGens(sample, fieldGenFuncs)
+	if err != nil {
+		return nil, err
+	}
+	f.preserve = preserve
+
+	nested, _, _, err := f.makeFieldGens(sample, nestedgens)
+	if err != nil {
+		return nil, err
+	}
+
+	// append nested proto generators unless there are explicit ones for the same fields
+	names := make(map[string]bool, len(fieldGens))
+	for _, fg := range fieldGens {
+		names[fg.Name] = true
+	}
+	for _, fg := range nested {
+		if !names[fg.Name] {
+			fieldGens = append(fieldGens, fg)
+		}
+	}
+
+	if f.fieldGens, err = f.sortFieldGens(fieldGens); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// makeFieldGens evaluates field generator funcs against the sample, expands traits placeholders
+// into the field generators of named traits, collects filters of Omit and Only placeholders
+// and the ones of Preserve placeholders and returns the first error met in placeholders.
+func (f *Factory) makeFieldGens(sample reflect.Value, fieldGenFuncs []FieldGenFunc) (
+	[]fieldWithGen, []func(string) bool, []func(string) bool, error,
+) {
+	traitGens := []fieldWithGen{}
 	fieldGens := make([]fieldWithGen, 0, len(fieldGenFuncs))
+	filters := []func(string) bool{}
+	preserve := []func(string) bool{}
 
-	// create field generators
 	for _, makeFieldGen := range fieldGenFuncs {
-		fieldGens = append(fieldGens, makeFieldGen(sample)...)
+		for _, fg := range makeFieldGen(sample) {
+			if fg.err != nil {
+				return nil, nil, nil, fg.err
+			}
+
+			if fg.keep != nil {
+				filters = append(filters, fg.keep)
+				continue
+			}
+
+			if fg.keepSet != nil {
+				preserve = append(preserve, fg.keepSet)
+				continue
+			}
+
+			if fg.traits == nil {
+				fieldGens = append(fieldGens, fg)
+				continue
+			}
+
+			for _, name := range fg.traits {
+				trait, ok := f.traits[name]
+				if !ok {
+					return nil, nil, nil, fmt.Errorf("trait %q not found in %s factory", name, f.typ.Name())
+				}
+				for _, makeTraitGen := range trait {
+					for _, tg := range makeTraitGen(sample) {
+						if tg.err != nil {
+							return nil, nil, nil, tg.err
+						}
+						if tg.traits != nil || tg.keep != nil || tg.keepSet != nil {
+							return nil, nil, nil, fmt.Errorf("trait %q can not include other traits or filters", name)
+						}
+						traitGens = append(traitGens, tg)
+					}
+				}
+			}
+		}
 	}
 
-	return &Factory{typ: typ, fieldGens: fieldGens}
+	return append(traitGens, fieldGens...), filters, preserve, nil
+}
+
+// keepFieldGen checks if all the filters keep generator of the named field
+func keepFieldGen(name string, filters []func(string) bool) bool {
+	for _, keep := range filters {
+		if !keep(name) {
+			return false
+		}
+	}
+	return true
 }
@@ -1,8 +1,14 @@
 package factory_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	randomdata "github.com/Pallinder/go-randomdata"
 	. "github.com/onsi/ginkgo"
@@ -38,6 +44,30 @@ type User struct {
 	s string
 }
 
+type Customer struct {
+	Name    string
+	Address *Address
+}
+
+type Base struct {
+	ID string
+}
+
+type Audit struct {
+	CreatedBy string
+}
+
+type audit struct {
+	UpdatedBy string
+}
+
+type Account struct {
+	Base
+	*Audit
+	*audit
+	Name string
+}
+
 var _ = Describe("Factory", func() {
 	var (
 		userFact *Factory
@@ -102,6 +132,55 @@ var _ = Describe("Factory", func() {
 		Ω(u.Address.Street).Should(Equal("Mexicali"))
 	})
 
+	It("should wrap generator errors with field name", func() {
+		boom := errors.New("boom")
+		f := addrFact.Derive(Use(func() (string, error) { return "", boom }).For("City"))
+		_, err := userFact.Create(Use(f).For("Address"))
+		Ω(err).Should(MatchError(`field "Address": field "City": boom`))
+
+		var fe *FieldError
+		Ω(errors.As(err, &fe)).Should(BeTrue())
+		Ω(fe.Field).Should(Equal("Address"))
+		Ω(errors.Is(err, boom)).Should(BeTrue())
+	})
+
+	Describe("sub-factory", func() {
+		It("should assign created pointer to pointer field", func() {
+			var created *Address
+			f := addrFact.Derive().AfterCreate(func(ctx Ctx) error {
+				created = ctx.Instance.(*Address)
+				return nil
+			})
+			c := NewFactory(Customer{}, Use(f).For("Address")).MustCreate().(*Customer)
+			Ω(c.Address).Should(BeIdenticalTo(created))
+			Ω(c.Address.City).Should(Equal("CDMX"))
+		})
+
+		It("should assign copy of created instance to value field", func() {
+			u := userFact.MustCreate().(*User)
+			Ω(u.Address.City).Should(Equal("CDMX"))
+		})
+
+		It("should allocate pointer for value returned by generator", func() {
+			c := NewFactory(Customer{}, Use(Address{City: "CDMX"}).For("Address")).MustCreate().(*Customer)
+			Ω(c.Address).Should(Equal(&Address{City: "CDMX"}))
+		})
+
+		It("should assign nil pointer to pointer field and zero value to value field", func() {
+			nilAddr := func() *Address { return nil }
+			c := NewFactory(Customer{}, Use(nilAddr).For("Address")).MustCreate().(*Customer)
+			Ω(c.Address).Should(BeNil())
+			u := userFact.MustCreate(Use(nilAddr).For("Address")).(*User)
+			Ω(u.Address).Should(Equal(Address{}))
+		})
+	})
+
+	It("should return error on nil sub-factory", func() {
+		var f *Factory
+		_, err := userFact.Create(Use(f).For("Address"))
+		Ω(err).Should(MatchError(`field "Address": nil sub-factory`))
+	})
+
 	It("should copy prototype properties", func() {
 		proto := User{Married: true, Age: 45, FirstName: "Nick", i: 5}
 		userFact := NewFactory(proto, Use("Smith").For("LastName"))
@@ -116,6 +195,184 @@ var _ = Describe("Factory", func() {
 		Ω(user.LastName).Should(Equal("Smith"))
 	})
 
+	It("should copy prototype with uncomparable nested fields", func() {
+		type Tags struct {
+			Names []string
+		}
+		type Post struct {
+			Title string
+			Tags  Tags
+			Empty Tags
+		}
+
+		var f *Factory
+		Ω(func() { f = NewFactory(Post{Tags: Tags{Names: []string{"go"}}}) }).ShouldNot(Panic())
+
+		p := f.MustCreate().(*Post)
+		Ω(p.Tags.Names).Should(Equal([]string{"go"}))
+		Ω(p.Empty.Names).Should(BeNil())
+	})
+
+	Describe("Unset", func() {
+		type Post struct {
+			Title string
+			Meta  map[string]string
+		}
+
+		It("should leave the field at proto value", func() {
+			f := NewFactory(Post{Meta: map[string]string{"lang": "go"}},
+				Use(func(ctx Ctx) (interface{}, error) {
+					if ctx.Index == 0 {
+						return Unset, nil
+					}
+					return nil, nil
+				}).For("Meta"),
+			)
+			posts := f.MustCreateN(2)
+			Ω(posts[0].(*Post).Meta).Should(Equal(map[string]string{"lang": "go"}))
+			Ω(posts[1].(*Post).Meta).Should(BeNil())
+		})
+
+		It("should leave the field of existing instance", func() {
+			user := User{Username: "john"}
+			userFact.MustSetFields(&user, Use(Unset).For("Username"))
+			Ω(user.Username).Should(Equal("john"))
+		})
+
+		It("should not fail strict nil check", func() {
+			user := User{Username: "john"}
+			Ω(userFact.StrictNil(true).SetFields(&user, Use(Unset).For("Username"))).Should(Succeed())
+			Ω(user.Username).Should(Equal("john"))
+		})
+	})
+
+	Describe("pinned proto fields", func() {
+		It("should take listed zero value fields from proto", func() {
+			f := NewFactory(
+				Proto(User{Age: 45}, "Married", "Address.City"),
+				Use(true).For("Married"),
+				Use(addrFact).For("Address"),
+			)
+
+			u := f.MustCreate().(*User)
+			Ω(u.Age).Should(Equal(45))
+			Ω(u.Address.City).Should(BeEmpty())
+			Ω(u.Address.Street).Should(Equal("Mexicali"))
+			// explicit generators still win
+			Ω(u.Married).Should(BeTrue())
+		})
+
+		It("should override base generators with zero values", func() {
+			f := userFact.Merge(NewFactory(Proto(User{}, "Married", "Age")))
+			u := f.MustCreate().(*User)
+			Ω(u.Married).Should(BeFalse())
+			Ω(u.Age).Should(BeZero())
+		})
+
+		It("should fail on not existing field", func() {
+			_, err := NewFactoryE(Proto(User{}, "Foo"))
+			Ω(err).Should(MatchError(`field "Foo" not found in User`))
+		})
+	})
+
+	Describe("FromProto", func() {
+		It("should tell the fields taken from proto", func() {
+			f := NewFactory(
+				Proto(User{Username: "john", Address: Address{City: "CDMX"}}, "Married"),
+				Use(30).For("Age"),
+			)
+			Ω(f.FromProto("Username")).Should(BeTrue())
+			Ω(f.FromProto("Address.City")).Should(BeTrue())
+			Ω(f.FromProto("Married")).Should(BeTrue())
+			Ω(f.FromProto("Age")).Should(BeFalse())
+			// zero value fields are not taken from proto
+			Ω(f.FromProto("Comment")).Should(BeFalse())
+		})
+
+		It("should not tell the overridden proto fields", func() {
+			f := NewFactory(User{Username: "john"}).Derive(Use("jane").For("Username"))
+			Ω(f.FromProto("Username")).Should(BeFalse())
+		})
+
+		It("should not tell the proto fields with generators passed along", func() {
+			f := NewFactory(User{Username: "john", Comment: "hi"}, Use("jane").For("Username"))
+			Ω(f.MustCreate().(*User).Username).Should(Equal("jane"))
+			Ω(f.FromProto("Username")).Should(BeFalse())
+			Ω(f.FromProto("Comment")).Should(BeTrue())
+		})
+
+		It("should tell the origin of fields to generators", func() {
+			origins := map[string]bool{}
+			NewFactory(User{Username: "john"}, Use(1).For("Age")).MustCreate(
+				Use(func(ctx Ctx) (interface{}, error) {
+					origins["Username"] = ctx.FromProto("Username")
+					origins["Age"] = ctx.FromProto("Age")
+					return "", nil
+				}).For("Comment"),
+			)
+			Ω(origins).Should(Equal(map[string]bool{"Username": true, "Age": false}))
+			Ω(Ctx{}.FromProto("Username")).Should(BeFalse())
+		})
+	})
+
+	It("should not share prototype slices and maps between instances", func() {
+		type Post struct {
+			Tags  []string
+			Votes map[string]int
+		}
+
+		f := NewFactory(Post{Tags: []string{"go", "test"}, Votes: map[string]int{"bob": 1}})
+
+		p1 := f.MustCreate().(*Post)
+		p1.Tags[0] = "rust"
+		p1.Votes["bob"] = 2
+
+		p2 := f.MustCreate().(*Post)
+		Ω(p2.Tags).Should(Equal([]string{"go", "test"}))
+		Ω(p2.Votes).Should(Equal(map[string]int{"bob": 1}))
+	})
+
+	Describe("nested proto fields", func() {
+		It("should pin nested fields and run sub-factory for the rest", func() {
+			f := NewFactory(User{Address: Address{City: "Cancun"}}, Use(addrFact).For("Address"))
+			u := f.MustCreate().(*User)
+			Ω(u.Address.City).Should(Equal("Cancun"))
+			Ω(u.Address.Street).Should(Equal("Mexicali"))
+		})
+
+		It("should let explicit nested generators win", func() {
+			f := NewFactory(User{Address: Address{City: "Cancun"}}, Use("Tulum").For("Address.City"))
+			Ω(f.MustCreate().(*User).Address.City).Should(Equal("Tulum"))
+		})
+
+		It("should copy structs with unexported fields as a whole", func() {
+			now := time.Now()
+			f := NewFactory(Event{CreatedAt: now})
+			Ω(f.MustCreate().(*Event).CreatedAt).Should(Equal(now))
+		})
+
+		It("should not share structs behind proto pointers", func() {
+			proto := Customer{Address: &Address{City: "Cancun"}}
+			f := NewFactory(proto, Use(SeqSelect("Tulum", "Merida")).For("Address.Street"))
+			a := f.MustCreate().(*Customer)
+			b := f.MustCreate().(*Customer)
+			Ω(a.Address).Should(Equal(&Address{City: "Cancun", Street: "Tulum"}))
+			Ω(b.Address).Should(Equal(&Address{City: "Cancun", Street: "Merida"}))
+			Ω(a.Address).ShouldNot(BeIdenticalTo(b.Address))
+			Ω(a.Address).ShouldNot(BeIdenticalTo(proto.Address))
+			Ω(proto.Address).Should(Equal(&Address{City: "Cancun"}))
+		})
+
+		It("should copy struct fields as a whole if recursion is off", func() {
+			RecurseProto = false
+			defer func() { RecurseProto = true }()
+
+			f := NewFactory(User{Address: Address{City: "Cancun"}}, Use(addrFact).For("Address"))
+			u := f.MustCreate().(*User)
+			Ω(u.Address.City).Should(Equal("CDMX"))
+		})
+	})
+
 	It("should create instances of given type", func() {
 		u, ok := userFact.MustCreate().(*User)
 		Ω(ok).Should(BeTrue())
@@ -155,6 +412,210 @@ var _ = Describe("Factory", func() {
 		Ω(u.Comment).Should(Equal("Blahblahblah")) // check new generator
 	})
 
+	Describe("Derive field order", func() {
+		var base *Factory
+
+		BeforeEach(func() {
+			base = NewFactory(Address{}, Use("CDMX").For("City"), Use("Main").For("Street"))
+		})
+
+		It("should keep position of overridden fields", func() {
+			f := base.Derive(Use("Merida").For("City"))
+			Ω(f.DescribeFields()).Should(Equal("City: value\nStreet: value\n"))
+			Ω(f.MustCreate().(*Address).City).Should(Equal("Merida"))
+		})
+
+		It("should append new fields in order of appearance", func() {
+			f := NewFactory(User{}, Use("john").For("Username")).Derive(Use("x").For("Comment"), Use(1).For("Age"))
+			Ω(f.Fields()).Should(Equal([]string{"Username", "Comment", "Age"}))
+		})
+
+		It("should take the last generator of field listed twice", func() {
+			f := base.Derive(Use("Merida").For("City"), Use("Tulum").For("City"))
+			Ω(f.Fields()).Should(Equal([]string{"City", "Street"}))
+			Ω(f.MustCreate().(*Address).City).Should(Equal("Tulum"))
+
+			u := NewFactory(User{}).Derive(Use("a").For("Comment"), Use("b").For("Comment"))
+			Ω(u.DescribeFields()).Should(Equal("Comment: value\n"))
+			Ω(u.MustCreate().(*User).Comment).Should(Equal("b"))
+		})
+
+		It("should override all base generators of field", func() {
+			f := NewFactory(Address{}, Use("CDMX").For("City"), Use("Main").For("Street"), Use("Cancun").For("City"))
+			d := f.Derive(Use("Tulum").For("City"))
+			Ω(d.DescribeFields()).Should(Equal("City: value\nStreet: value\n"))
+			Ω(d.MustCreate().(*Address).City).Should(Equal("Tulum"))
+		})
+	})
+
+	Describe("UseSame", func() {
+		It("should assign the same value to all fields", func() {
+			f := userFact.Derive(UseSame(SeqSelect("a", "b")).For("Username", "Comment", "Address.City"))
+			for _, i := range f.MustCreateN(2) {
+				u := i.(*User)
+				Ω(u.Comment).Should(Equal(u.Username))
+				Ω(u.Address.City).Should(Equal(u.Username))
+			}
+		})
+
+		It("should copy value regardless of the order of base generators", func() {
+			f := NewFactory(User{}, Use("x").For("Comment"))
+			u := f.MustCreate(UseSame(randomdata.FirstName, randomdata.Male).For("Username", "Comment")).(*User)
+			Ω(u.Comment).Should(Equal(u.Username))
+		})
+	})
+
+	Describe("ForIndex", func() {
+		It("should bind generators to fields by index path", func() {
+			typ := reflect.TypeOf(User{})
+			username, _ := typ.FieldByName("Username")
+			city, _ := typ.FieldByName("Address")
+			f := NewFactory(User{},
+				Use("john").ForIndex(username.Index),
+				Use("CDMX").ForIndex(append(city.Index, 0)),
+			)
+			Ω(f.Fields()).Should(Equal([]string{"Username", "Address.City"}))
+
+			u := f.MustCreate().(*User)
+			Ω(u.Username).Should(Equal("john"))
+			Ω(u.Address.City).Should(Equal("CDMX"))
+		})
+
+		It("should override generators bound by name", func() {
+			typ := reflect.TypeOf(User{})
+			username, _ := typ.FieldByName("Username")
+			u := userFact.MustCreate(Use("jane").ForIndex(username.Index)).(*User)
+			Ω(u.Username).Should(Equal("jane"))
+		})
+
+		It("should panic on fields that are not found or can not be set", func() {
+			Ω(func() { NewFactory(User{}, Use(1).ForIndex([]int{100})) }).Should(PanicWithError(errors.New("field index [100] not found in User")))
+			Ω(func() { NewFactory(User{}, Use(1).ForIndex([]int{1, 0})) }).Should(PanicWithError(errors.New("field index [1 0] not found in User")))
+			Ω(func() { NewFactory(User{}, Use(1).ForIndex([]int{9})) }).Should(PanicWithError(errors.New(`field "i" can not be set in User`)))
+		})
+	})
+
+	Describe("static values", func() {
+		It("should override static values with generators and back", func() {
+			f := NewFactory(User{Comment: "proto"}, Use("john").For("Username"))
+			d := f.Derive(Use(func() string { return "jane" }).For("Username", "Comment"))
+			u := d.MustCreate().(*User)
+			Ω(u.Username).Should(Equal("jane"))
+			Ω(u.Comment).Should(Equal("jane"))
+
+			u = d.Derive(Use("bob").For("Username")).MustCreate().(*User)
+			Ω(u.Username).Should(Equal("bob"))
+			Ω(u.Comment).Should(Equal("jane"))
+		})
+
+		It("should convert static values to field types", func() {
+			u := NewFactory(User{}, Use(int8(30)).For("Age"), Use(nil).For("Username")).MustCreate().(*User)
+			Ω(u.Age).Should(Equal(30))
+			Ω(u.Username).Should(BeEmpty())
+		})
+
+		It("should keep errors of static values that can't be assigned", func() {
+			_, err := NewFactory(User{}, Use("x").For("Age")).Create()
+			Ω(err).Should(MatchError(`field "Age": cannot assign string to int`))
+
+			_, err = NewFactory(User{}, Use(nil).For("Username")).StrictNil(true).Create()
+			Ω(err).Should(MatchError(`field "Username": cannot assign nil to string`))
+		})
+
+		It("should share static values holding references between instances", func() {
+			type Post struct {
+				Tags  []string
+				Votes map[string]int
+			}
+			f := NewFactory(Post{}, Use([]string{"go"}).For("Tags"), Use(map[string]int{"bob": 1}).For("Votes"))
+			p1, p2 := f.MustCreate().(*Post), f.MustCreate().(*Post)
+			p1.Tags[0] = "test"
+			p1.Votes["bob"] = 2
+			Ω(p2.Tags).Should(Equal([]string{"test"}))
+			Ω(p2.Votes).Should(Equal(map[string]int{"bob": 2}))
+		})
+
+		It("should bind factory to context of generators and hooks along with static values", func() {
+			depths := []int{}
+			depth := func(ctx Ctx) error {
+				depths = append(depths, ctx.Factory.CallDepth())
+				return nil
+			}
+			f := NewFactory(User{}, Use("john").For("Username"), Use(func(ctx Ctx) (interface{}, error) {
+				return "", depth(ctx)
+			}).For("Comment")).BeforeCreate(depth).AfterCreate(depth)
+			f.MustCreate()
+			Ω(depths).Should(Equal([]int{1, 1, 1}))
+
+			depths = nil
+			NewFactory(User{}, Use("john").For("Username")).AfterCreate(depth).MustCreate()
+			Ω(depths).Should(Equal([]int{1}))
+		})
+	})
+
+	Describe("UseOptional", func() {
+		shared := []FieldGenFunc{
+			UseOptional("john").For("Username", "Nickname"),
+			UseOptional("Tulum").For("City", "Address.City"),
+		}
+
+		It("should skip fields missing in the type", func() {
+			u := NewFactory(User{}, shared...).MustCreate().(*User)
+			Ω(u.Username).Should(Equal("john"))
+			Ω(u.Address.City).Should(Equal("Tulum"))
+
+			a := NewFactory(Address{}, shared...).MustCreate().(*Address)
+			Ω(a.City).Should(Equal("Tulum"))
+		})
+
+		It("should panic on fields that can not be set", func() {
+			Ω(func() { NewFactory(User{}, UseOptional(1).For("i")) }).Should(Panic())
+		})
+	})
+
+	It("should tell the type of instances", func() {
+		Ω(userFact.Type()).Should(Equal(reflect.TypeOf(User{})))
+		Ω(userFact.Derive().Type()).Should(Equal(reflect.TypeOf(User{})))
+	})
+
+	It("should list generated fields in order of registration", func() {
+		Ω(userFact.Fields()).Should(Equal([]string{
+			"ID", "Username", "FirstName", "LastName", "Email", "Married", "Age", "Address",
+		}))
+		f := userFact.Derive(Use("x").For("Comment", "Address.City", "Username"))
+		Ω(f.Fields()).Should(Equal([]string{
+			"ID", "Username", "FirstName", "LastName", "Email", "Married", "Age", "Address", "Comment", "Address.City",
+		}))
+	})
+
+	It("should describe field generators", func() {
+		f := NewFactory(
+			User{Age: 30},
+			Use(uuid.NewV4).For("ID"),
+			Use("john", "jane").For("Username"),
+			UseSame("Doe").For("LastName", "Comment"),
+			Use(addrFact).For("Address"),
+			WithGen(func(Ctx) (interface{}, error) { return "x", nil }, "Email"),
+		)
+		Ω(f.DescribeFields()).Should(Equal(`Age: proto
+ID: func
+Username: select
+LastName: value
+Comment: copy (depends on LastName)
+Address: factory
+Email: generator
+`))
+	})
+
+	It("should override fields with chained With calls", func() {
+		f := userFact.With("Username", "jane").With("Age", randomdata.Number, 30, 31)
+		u := f.MustCreate().(*User)
+		Ω(u.Username).Should(Equal("jane"))
+		Ω(u.FirstName).Should(Equal("Jane"))
+		Ω(u.Age).Should(Equal(30))
+		Ω(userFact.MustCreate().(*User).Username).ShouldNot(Equal("jane"))
+	})
+
 	It("should support generator funcs that return error as second value", func() {
 		_, err := userFact.Create(
 			Use(func() (string, error) {
@@ -197,6 +658,58 @@ var _ = Describe("Factory", func() {
 		Ω(call).Should(PanicWithError(errors.New("not enough input arguments to make a function call. Expected: 2, was: 1")))
 	})
 
+	It("should return generator function errors with NewGeneratorE", func() {
+		_, err := NewGeneratorE(func(i int, b bool) string { return "John" }, 4)
+		Ω(err).Should(MatchError("not enough input arguments to make a function call. Expected: 2, was: 1"))
+
+		_, err = NewGeneratorE(func() (string, int) { return "John", 1 })
+		Ω(err).Should(MatchError("expect second returned type implement error but found: int"))
+
+		_, err = NewGeneratorE(func() {})
+		Ω(err).Should(MatchError("expect function to return 1 or 2 values but was: 0"))
+
+		gen, err := NewGeneratorE(func(s string) string { return s }, "John")
+		Ω(err).Should(BeNil())
+		Ω(gen(Ctx{})).Should(Equal("John"))
+	})
+
+	Describe("variadic generator functions", func() {
+		join := func(prefix string, nums ...int) string {
+			return fmt.Sprintf("%s%v", prefix, nums)
+		}
+
+		It("should pack arguments beyond fixed parameters into variadic one", func() {
+			Ω(NewGenerator(join, "n", 1, 2, 3)(Ctx{})).Should(Equal("n[1 2 3]"))
+			Ω(NewGenerator(join, "n", 1)(Ctx{})).Should(Equal("n[1]"))
+			Ω(NewGenerator(join, "n")(Ctx{})).Should(Equal("n[]"))
+			u := userFact.MustCreate(Use(join, "user", 4, 2).For("Username")).(*User)
+			Ω(u.Username).Should(Equal("user[4 2]"))
+		})
+
+		It("should pass slice as variadic parameter", func() {
+			Ω(NewGenerator(join, "n", []int{1, 2})(Ctx{})).Should(Equal("n[1 2]"))
+		})
+
+		It("should return error on missing fixed arguments and wrong types", func() {
+			_, err := NewGeneratorE(join)
+			Ω(err).Should(MatchError("not enough input arguments to make a function call. Expected at least: 1, was: 0"))
+
+			_, err = NewGeneratorE(join, "n", 1, "2")
+			Ω(err).Should(MatchError("expect argument 2 of type int but was: string"))
+
+			_, err = NewGeneratorE(join, 1)
+			Ω(err).Should(MatchError("expect argument 0 of type string but was: int"))
+		})
+	})
+
+	It("should make factory from NewGeneratorE generators without panic", func() {
+		gen, err := NewGeneratorE("john", "jane")
+		Ω(err).Should(BeNil())
+		f, err := NewFactoryE(User{}, WithGenE(gen, "Username"))
+		Ω(err).Should(BeNil())
+		Ω(f.MustCreate().(*User).Username).Should(BelongTo("john", "jane"))
+	})
+
 	It("should panic on attempt to set unexported field", func() {
 		Ω(func() { userFact.Create(Use(1).For("i")) }).Should(PanicWithError(errors.New("field \"i\" can not be set in User")))
 	})
@@ -205,6 +718,813 @@ var _ = Describe("Factory", func() {
 		Ω(func() { userFact.Create(Use(1).For("foobar")) }).Should(PanicWithError(errors.New("field \"foobar\" not found in User")))
 	})
 
+	Describe("NewFactoryE and WithGenE", func() {
+		It("should create factory", func() {
+			f, err := NewFactoryE(User{}, WithGenE(NewGenerator("john"), "Username"))
+			Ω(err).Should(BeNil())
+			Ω(f.MustCreate().(*User).Username).Should(Equal("john"))
+		})
+
+		It("should return error on not existing field", func() {
+			f, err := NewFactoryE(User{}, WithGenE(NewGenerator(1), "foobar"))
+			Ω(err).Should(MatchError(`field "foobar" not found in User`))
+			Ω(f).Should(BeNil())
+		})
+
+		It("should return error on unexported field", func() {
+			_, err := NewFactoryE(User{}, WithGenE(NewGenerator(1), "i"))
+			Ω(err).Should(MatchError(`field "i" can not be set in User`))
+		})
+
+		It("should return error on create", func() {
+			_, err := userFact.Create(WithGenE(NewGenerator(1), "Address.i"))
+			Ω(err).Should(MatchError(`field "Address.i" can not be set in User`))
+		})
+
+		It("should panic in NewFactory", func() {
+			Ω(func() { NewFactory(User{}, WithGenE(NewGenerator(1), "i")) }).Should(PanicWithError(errors.New(`field "i" can not be set in User`)))
+		})
+	})
+
+	Describe("nested fields", func() {
+		It("should set field of nested struct", func() {
+			u := userFact.MustCreate(Use("Cancun").For("Address.City")).(*User)
+			Ω(u.Address.City).Should(Equal("Cancun"))
+			Ω(u.Address.Street).Should(Equal("Mexicali"))
+		})
+
+		It("should allocate nil pointers to nested structs", func() {
+			f := NewFactory(Customer{}, Use("Cancun").For("Address.City"))
+			c := f.MustCreate().(*Customer)
+			Ω(c.Address).ShouldNot(BeNil())
+			Ω(c.Address.City).Should(Equal("Cancun"))
+		})
+
+		It("should panic if any segment of the path is not found", func() {
+			Ω(func() { userFact.Create(Use(1).For("Address.foobar")) }).Should(PanicWithError(errors.New("field \"Address.foobar\" not found in User")))
+			Ω(func() { userFact.Create(Use(1).For("Username.foobar")) }).Should(PanicWithError(errors.New("field \"Username.foobar\" not found in User")))
+		})
+
+		It("should panic if any segment of the path is unexported", func() {
+			Ω(func() { userFact.Create(Use(1).For("Address.i")) }).Should(PanicWithError(errors.New("field \"Address.i\" can not be set in User")))
+		})
+	})
+
+	Describe("WithRand", func() {
+		It("should generate reproducible instances", func() {
+			create := func() []interface{} {
+				return userFact.WithRand(rand.New(rand.NewSource(42))).MustCreateN(10)
+			}
+			users1, users2 := create(), create()
+			for i := range users1 {
+				u1, u2 := users1[i].(*User), users2[i].(*User)
+				Ω(u1.Username).Should(Equal(u2.Username))
+				Ω(u1.LastName).Should(Equal(u2.LastName))
+			}
+		})
+
+		It("should make RndSelect reproducible", func() {
+			f := userFact.Derive(Use(RndSelect("a", "b", "c", "d", "e")).For("Comment"))
+			create := func() []string {
+				comments := []string{}
+				for _, u := range f.WithRand(rand.New(rand.NewSource(42))).MustCreateN(20) {
+					comments = append(comments, u.(*User).Comment)
+				}
+				return comments
+			}
+			Ω(create()).Should(Equal(create()))
+		})
+
+		It("should expose random source to generators", func() {
+			var n int
+			userFact.Derive(Only()).WithRand(rand.New(rand.NewSource(42))).MustCreate(
+				Use(func(ctx Ctx) (interface{}, error) {
+					n = ctx.Rand.Int()
+					return "", nil
+				}).For("Comment"),
+			)
+			Ω(n).Should(Equal(rand.New(rand.NewSource(42)).Int()))
+		})
+
+		It("should be safe to use seeded factory concurrently", func() {
+			f := userFact.Derive(
+				Use(RndSelect("a", "b", "c")).For("Comment"),
+				Use(Select(Rnd, "x", "y")).For("LastName"),
+			).WithRand(rand.New(rand.NewSource(42)))
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					for i := 0; i < 50; i++ {
+						u := f.MustCreate().(*User)
+						Ω(u.Comment).Should(BelongTo("a", "b", "c"))
+						Ω(u.LastName).Should(BelongTo("x", "y"))
+					}
+				}()
+			}
+			wg.Wait()
+		})
+
+		It("should expose default random source to generators", func() {
+			u := userFact.MustCreate(
+				Use(func(ctx Ctx) (interface{}, error) {
+					return ctx.Rand.Intn(10), nil
+				}).For("Age"),
+			).(*User)
+			Ω(u.Age).Should(And(BeNumerically(">=", 0), BeNumerically("<", 10)))
+		})
+
+		It("should make custom generators reproducible", func() {
+			f := userFact.Derive(Use(func(ctx Ctx) (interface{}, error) {
+				return ctx.Rand.Intn(1000), nil
+			}).For("Age"))
+			create := func() *User {
+				return f.WithRand(rand.New(rand.NewSource(7))).MustCreate().(*User)
+			}
+			Ω(create().Age).Should(Equal(create().Age))
+		})
+	})
+
+	Describe("BeforeCreate", func() {
+		It("should call hooks in order before field generators", func() {
+			calls := []string{}
+			f := userFact.Derive().BeforeCreate(func(ctx Ctx) error {
+				u := ctx.Instance.(*User)
+				Ω(u.Username).Should(BeEmpty())
+				u.Comment = "stamped"
+				calls = append(calls, "first")
+				return nil
+			}).BeforeCreate(func(ctx Ctx) error {
+				calls = append(calls, "second")
+				return nil
+			})
+
+			u := f.MustCreate().(*User)
+			Ω(u.Comment).Should(Equal("stamped"))
+			Ω(calls).Should(Equal([]string{"first", "second"}))
+		})
+
+		It("should abort generation on hook error", func() {
+			generated := false
+			f := userFact.Derive(
+				Use(func(ctx Ctx) (interface{}, error) {
+					generated = true
+					return "", nil
+				}).For("Comment"),
+			).BeforeCreate(func(ctx Ctx) error {
+				return errors.New("boom")
+			})
+			_, err := f.Create()
+			Ω(err).Should(MatchError("boom"))
+			Ω(generated).Should(BeFalse())
+		})
+	})
+
+	Describe("AfterCreate", func() {
+		It("should call hooks in order after field generators", func() {
+			calls := []string{}
+			f := userFact.Derive().AfterCreate(func(ctx Ctx) error {
+				u := ctx.Instance.(*User)
+				Ω(u.Email).Should(Equal(u.Username + "@6river.com"))
+				u.Email = strings.ToUpper(u.Email)
+				calls = append(calls, "first")
+				return nil
+			}).AfterCreate(func(ctx Ctx) error {
+				calls = append(calls, "second")
+				return nil
+			})
+
+			u := f.MustCreate().(*User)
+			Ω(u.Email).Should(Equal(strings.ToUpper(u.Username + "@6river.com")))
+			Ω(calls).Should(Equal([]string{"first", "second"}))
+		})
+
+		It("should return hook error", func() {
+			f := userFact.Derive().AfterCreate(func(ctx Ctx) error {
+				return errors.New("boom")
+			})
+			_, err := f.Create()
+			Ω(err).Should(MatchError("boom"))
+		})
+
+		It("should not leak hooks into parent factory", func() {
+			f := userFact.Derive()
+			f.AfterCreate(func(ctx Ctx) error { return errors.New("boom") })
+			_, err := userFact.Create()
+			Ω(err).Should(BeNil())
+		})
+	})
+
+	Describe("WithValidator", func() {
+		adult := func(i interface{}) error {
+			if i.(*User).Age < 18 {
+				return errors.New("user is not adult")
+			}
+			return nil
+		}
+
+		It("should return validation error", func() {
+			_, err := userFact.WithValidator(adult).Create(Use(10).For("Age"))
+			Ω(err).Should(MatchError("user is not adult"))
+		})
+
+		It("should validate instance after hooks", func() {
+			f := userFact.Derive(Use(10).For("Age")).AfterCreate(func(ctx Ctx) error {
+				ctx.Instance.(*User).Age = 18
+				return nil
+			})
+			_, err := f.WithValidator(adult).Create()
+			Ω(err).Should(BeNil())
+		})
+
+		It("should not add validator to the original factory", func() {
+			userFact.WithValidator(adult)
+			_, err := userFact.Create(Use(10).For("Age"))
+			Ω(err).Should(BeNil())
+		})
+	})
+
+	Describe("WithCollector", func() {
+		var collected []interface{}
+
+		collect := func(i interface{}) { collected = append(collected, i) }
+
+		BeforeEach(func() {
+			collected = nil
+		})
+
+		It("should collect created instances", func() {
+			users := userFact.WithCollector(collect).MustCreateN(3)
+			Ω(collected).Should(Equal(users))
+		})
+
+		It("should collect instances created recursively", func() {
+			f := NewFactory(Node{}, Use(func(ctx Ctx) (interface{}, error) {
+				if ctx.Factory.CallDepth() > 1 {
+					return nil, nil
+				}
+				kids := []*Node{{}, {}, {}}
+				for _, kid := range kids {
+					if err := ctx.Factory.SetFields(kid); err != nil {
+						return nil, err
+					}
+				}
+				return kids, nil
+			}).For("Children")).WithCollector(collect)
+
+			root := f.MustCreate().(*Node)
+			Ω(collected).Should(HaveLen(4))
+			Ω(collected[3]).Should(Equal(root))
+		})
+
+		It("should collect instances after hooks and skip invalid ones", func() {
+			f := userFact.AfterCreate(func(ctx Ctx) error {
+				ctx.Instance.(*User).Comment = "checked"
+				return nil
+			}).WithValidator(func(i interface{}) error {
+				if i.(*User).Age < 18 {
+					return errors.New("user is not adult")
+				}
+				return nil
+			}).WithCollector(collect)
+
+			_, err := f.Create(Use(10).For("Age"))
+			Ω(err).Should(HaveOccurred())
+			Ω(collected).Should(BeEmpty())
+
+			f.MustCreate(Use(20).For("Age"))
+			Ω(collected).Should(HaveLen(1))
+			Ω(collected[0].(*User).Comment).Should(Equal("checked"))
+		})
+
+		It("should not add collector to the original factory", func() {
+			userFact.WithCollector(collect)
+			userFact.MustCreate()
+			Ω(collected).Should(BeEmpty())
+		})
+	})
+
+	Describe("CreateCtx and SetFieldsCtx", func() {
+		type key struct{}
+
+		It("should pass context to generators", func() {
+			c := context.WithValue(context.Background(), key{}, "tenant")
+			u, err := userFact.CreateCtx(c, Use(func(ctx Ctx) (interface{}, error) {
+				return ctx.Context.Value(key{}), nil
+			}).For("Comment"))
+			Ω(err).Should(BeNil())
+			Ω(u.(*User).Comment).Should(Equal("tenant"))
+		})
+
+		It("should pass context to recursive calls and sub-factories", func() {
+			values := []interface{}{}
+			addr := NewFactory(Address{}, Use(func(ctx Ctx) (interface{}, error) {
+				values = append(values, ctx.Context.Value(key{}))
+				return "CDMX", nil
+			}).For("City"))
+			node := NewFactory(Node{}, Use(func(ctx Ctx) (interface{}, error) {
+				values = append(values, ctx.Context.Value(key{}))
+				if ctx.Factory.CallDepth() > 1 {
+					return nil, nil
+				}
+				kid := &Node{}
+				return []*Node{kid}, ctx.Factory.SetFields(kid)
+			}).For("Children"))
+
+			c := context.WithValue(context.Background(), key{}, "tenant")
+			var u User
+			Ω(userFact.SetFieldsCtx(c, &u, Use(addr).For("Address"))).Should(Succeed())
+			_, err := node.CreateCtx(c)
+			Ω(err).Should(BeNil())
+			Ω(values).Should(Equal([]interface{}{"tenant", "tenant", "tenant"}))
+		})
+
+		It("should use background context by default", func() {
+			u := userFact.MustCreate(Use(func(ctx Ctx) (interface{}, error) {
+				Ω(ctx.Context).Should(Equal(context.Background()))
+				return "", nil
+			}).For("Comment"))
+			Ω(u).ShouldNot(BeNil())
+		})
+
+		It("should return error on cancelled context", func() {
+			c, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := userFact.CreateCtx(c)
+			Ω(err).Should(MatchError(context.Canceled))
+		})
+	})
+
+	Describe("Clone", func() {
+		It("should produce the same instances", func() {
+			u := userFact.Clone().MustCreate().(*User)
+			Ω(u.Username).Should(BelongTo("john", "james", "bob", "paul"))
+			Ω(u.Email).Should(Equal(u.Username + "@6river.com"))
+		})
+
+		It("should not leak registrations into original factory", func() {
+			base := userFact.RegisterTrait("jane", Use("jane").For("Username"))
+			clone := base.Clone().
+				RegisterTrait("jane", Use("janet").For("Username")).
+				AfterCreate(func(ctx Ctx) error { return errors.New("boom") })
+
+			_, err := clone.Create(WithTraits("jane"))
+			Ω(err).Should(MatchError("boom"))
+
+			u, err := base.Create(WithTraits("jane"))
+			Ω(err).Should(BeNil())
+			Ω(u.(*User).Username).Should(Equal("jane"))
+		})
+	})
+
+	Describe("DependsOn", func() {
+		email := func(ctx Ctx) (interface{}, error) {
+			return ctx.Instance.(*User).Username + "@6river.com", nil
+		}
+
+		It("should generate dependencies first regardless of registration order", func() {
+			f := NewFactory(
+				User{},
+				Use(email).For("Email").DependsOn("Username"),
+				Use("john").For("Username"),
+			)
+			Ω(f.MustCreate().(*User).Email).Should(Equal("john@6river.com"))
+		})
+
+		It("should keep dependencies on derive", func() {
+			f := NewFactory(User{}, Use("john").For("Username")).Derive(
+				Use(email).For("Email").DependsOn("Username"),
+				Use("jane").For("Username"),
+			)
+			Ω(f.MustCreate().(*User).Email).Should(Equal("jane@6river.com"))
+
+			u := f.MustCreate(
+				Use(func(ctx Ctx) (interface{}, error) {
+					return ctx.Instance.(*User).Email, nil
+				}).For("Comment").DependsOn("Email"),
+				Use(email).For("Email").DependsOn("Username"),
+			).(*User)
+			Ω(u.Comment).Should(Equal("jane@6river.com"))
+		})
+
+		It("should detect dependency cycles", func() {
+			_, err := NewFactoryE(
+				User{},
+				Use("a").For("FirstName").DependsOn("LastName"),
+				Use("b").For("LastName").DependsOn("FirstName"),
+			)
+			Ω(err).Should(MatchError(`dependency cycle among fields "FirstName", "LastName"`))
+
+			_, err = userFact.Create(
+				Use("a").For("Username").DependsOn("Email"),
+				Use(email).For("Email").DependsOn("Username"),
+			)
+			Ω(err).Should(MatchError(`dependency cycle among fields "Email", "Username"`))
+
+			// the fields are listed in the same order whatever order they are registered in
+			_, err = userFact.Create(
+				Use(email).For("Email").DependsOn("Username"),
+				Use("a").For("Username").DependsOn("Email"),
+			)
+			Ω(err).Should(MatchError(`dependency cycle among fields "Email", "Username"`))
+		})
+
+		It("should return error on dependency on unknown field", func() {
+			_, err := NewFactoryE(User{}, Use(email).For("Email").DependsOn("Usrname"))
+			Ω(err).Should(MatchError(`dependency of field "Email": field "Usrname" not found in User`))
+
+			_, err = userFact.Create(Use(email).For("Email").DependsOn("Address.Town"))
+			Ω(err).Should(MatchError(`dependency of field "Email": field "Address.Town" not found in User`))
+		})
+
+		It("should allow dependency on field without generator", func() {
+			f := NewFactory(User{}, Use("john").For("Username"), Use(email).For("Email").DependsOn("Username"))
+			Ω(f.MustCreate(Omit("Username")).(*User).Email).Should(Equal("@6river.com"))
+		})
+	})
+
+	Describe("Omit", func() {
+		It("should leave omitted fields at zero value", func() {
+			u := userFact.MustCreate(Omit("Email", "Married")).(*User)
+			Ω(u.Username).ShouldNot(BeEmpty())
+			Ω(u.Email).Should(BeEmpty())
+			Ω(u.Married).Should(BeFalse())
+		})
+
+		It("should omit nested fields", func() {
+			f := NewFactory(User{Address: Address{City: "Cancun"}}, Use(addrFact).For("Address"))
+			u := f.MustCreate(Omit("Address")).(*User)
+			Ω(u.Address).Should(Equal(Address{}))
+		})
+
+		It("should keep generators passed along", func() {
+			u := userFact.MustCreate(Omit("Email"), Use("foo@bar.com").For("Email")).(*User)
+			Ω(u.Email).Should(Equal("foo@bar.com"))
+		})
+
+		It("should not affect the factory", func() {
+			userFact.MustCreate(Omit("Email"))
+			Ω(userFact.MustCreate().(*User).Email).ShouldNot(BeEmpty())
+		})
+	})
+
+	Describe("Only", func() {
+		It("should generate only listed fields", func() {
+			u := userFact.MustCreate(Only("Username", "Address")).(*User)
+			Ω(u.Username).ShouldNot(BeEmpty())
+			Ω(u.Address.City).Should(Equal("CDMX"))
+			Ω(u.FirstName).Should(BeEmpty())
+			Ω(u.Email).Should(BeEmpty())
+			Ω(u.Age).Should(BeZero())
+		})
+
+		It("should compose with overrides", func() {
+			u := userFact.MustCreate(Only("FirstName", "LastName"), Use("X").For("FirstName")).(*User)
+			Ω(u.FirstName).Should(Equal("X"))
+			Ω(u.LastName).Should(BelongTo("Doe", "Smith", "Roy"))
+			Ω(u.Username).Should(BeEmpty())
+		})
+	})
+
+	Describe("Preserve", func() {
+		It("should generate only blank fields", func() {
+			u := User{Username: "jane", Address: Address{City: "Tulum"}}
+			Ω(userFact.SetFields(&u, Preserve())).Should(Succeed())
+			Ω(u.Username).Should(Equal("jane"))
+			Ω(u.Address).Should(Equal(Address{City: "Tulum"}))
+			Ω(u.FirstName).ShouldNot(BeEmpty())
+			Ω(u.Age).ShouldNot(BeZero())
+		})
+
+		It("should preserve listed fields only", func() {
+			u := User{Username: "jane", Email: "jane@mail.com"}
+			Ω(userFact.SetFields(&u, Preserve("Username"))).Should(Succeed())
+			Ω(u.Username).Should(Equal("jane"))
+			Ω(u.Email).Should(Equal("jane@6river.com"))
+		})
+
+		It("should preserve nested fields", func() {
+			f := userFact.Derive(Use("Main").For("Address.Street"))
+			u := User{Address: Address{City: "Tulum"}}
+			Ω(f.SetFields(&u, Preserve("Address"))).Should(Succeed())
+			Ω(u.Address).Should(Equal(Address{City: "Tulum", Street: "Main"}))
+		})
+
+		It("should compose with overrides", func() {
+			u := User{Username: "jane"}
+			Ω(userFact.SetFields(&u, Preserve(), Use("x").For("Username", "Comment"))).Should(Succeed())
+			Ω(u.Username).Should(Equal("jane"))
+			Ω(u.Comment).Should(Equal("x"))
+		})
+
+		It("should check fields before generators run", func() {
+			f := NewFactory(User{Comment: "proto"}, Use("generated").For("Comment"))
+			u := User{}
+			Ω(f.SetFields(&u, Preserve())).Should(Succeed())
+			Ω(u.Comment).Should(Equal("generated"))
+		})
+
+		It("should generate fields behind nil pointers", func() {
+			type Account struct {
+				Owner *User
+			}
+			f := NewFactory(Account{}, Use("john").For("Owner.Username"))
+			a := Account{}
+			Ω(f.SetFields(&a, Preserve())).Should(Succeed())
+			Ω(a.Owner.Username).Should(Equal("john"))
+		})
+
+		It("should preserve map entries", func() {
+			f := NewFactory(map[string]interface{}{}, Use("john").For("author", "owner", "editor"))
+			doc := map[string]interface{}{"author": "jane", "owner": nil}
+			Ω(f.SetFields(&doc, Preserve())).Should(Succeed())
+			Ω(doc).Should(Equal(map[string]interface{}{"author": "jane", "owner": "john", "editor": "john"}))
+		})
+
+		It("should not affect the factory", func() {
+			u := User{Username: "jane"}
+			userFact.MustSetFields(&u, Preserve())
+			userFact.MustSetFields(&u)
+			Ω(u.Username).ShouldNot(Equal("jane"))
+		})
+	})
+
+	Describe("Merge", func() {
+		It("should combine generators with the merged ones winning", func() {
+			audit := NewFactory(User{}, Use("audited").For("Comment"), Use(99).For("Age"))
+			u := userFact.Merge(audit).MustCreate().(*User)
+			Ω(u.Username).Should(BelongTo("john", "james", "bob", "paul"))
+			Ω(u.Comment).Should(Equal("audited"))
+			Ω(u.Age).Should(Equal(99))
+		})
+
+		It("should combine hooks in order", func() {
+			calls := []string{}
+			a := userFact.Clone().AfterCreate(func(Ctx) error { calls = append(calls, "a"); return nil })
+			b := NewFactory(User{}).AfterCreate(func(Ctx) error { calls = append(calls, "b"); return nil })
+			a.Merge(b).MustCreate()
+			Ω(calls).Should(Equal([]string{"a", "b"}))
+		})
+
+		It("should take settings of merged factory if they are set", func() {
+			seeded := func() *rand.Rand { return rand.New(rand.NewSource(42)) }
+			names := RndSelect("a", "b", "c", "d", "e")
+			expected := NewFactory(User{}, Use(names).For("Comment")).WithRand(seeded()).MustCreateN(10)
+
+			m := NewFactory(User{}, Use(names).For("Comment")).Merge(NewFactory(User{}).WithRand(seeded()).WithMaxDepth(2).StrictNil(true))
+			Ω(m.MaxDepth()).Should(Equal(2))
+			Ω(m.MustCreateN(10)).Should(Equal(expected))
+			_, err := m.Create(Use(nil).For("Comment"))
+			Ω(err).Should(MatchError(`field "Comment": cannot assign nil to string`))
+
+			registry := NewRegistry(addrFact)
+			c := NewFactory(Company{}).Merge(NewFactory(Company{}).WithRegistry(registry)).MustCreate().(*Company)
+			Ω(c.Address).ShouldNot(BeNil())
+
+			// the settings of receiver are kept if merged factory has none
+			m = userFact.WithMaxDepth(3).StrictNil(true).Merge(NewFactory(User{}))
+			Ω(m.MaxDepth()).Should(Equal(3))
+			_, err = m.Create(Use(nil).For("Comment"))
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("should panic on different types", func() {
+			Ω(func() { userFact.Merge(addrFact) }).Should(PanicWithError(errors.New(
+				"can not merge factory_test.User factory with factory_test.Address factory",
+			)))
+		})
+	})
+
+	Describe("pointer to struct fields", func() {
+		It("should allocate pointer for struct value", func() {
+			addr := Address{City: "CDMX", Street: "Mexicali"}
+			c := NewFactory(Customer{}, Use(func() Address { return addr }).For("Address")).MustCreate().(*Customer)
+			Ω(c.Address).Should(Equal(&addr))
+		})
+
+		It("should allocate new pointer for each instance", func() {
+			f := NewFactory(Customer{}, Use(Address{City: "CDMX"}).For("Address"))
+			c1, c2 := f.MustCreate().(*Customer), f.MustCreate().(*Customer)
+			c1.Address.City = "Cancun"
+			Ω(c2.Address.City).Should(Equal("CDMX"))
+		})
+	})
+
+	Describe("embedded structs", func() {
+		It("should set promoted fields of value and pointer embedded structs", func() {
+			a := NewFactory(
+				Account{},
+				Use("42").For("ID"),
+				Use("admin").For("CreatedBy"),
+			).MustCreate().(*Account)
+			Ω(a.ID).Should(Equal("42"))
+			Ω(a.Audit).ShouldNot(BeNil())
+			Ω(a.CreatedBy).Should(Equal("admin"))
+		})
+
+		It("should set fields addressed via embedded struct name", func() {
+			a := NewFactory(Account{}, Use("42").For("Base.ID"), Use("admin").For("Audit.CreatedBy")).MustCreate().(*Account)
+			Ω(a.ID).Should(Equal("42"))
+			Ω(a.CreatedBy).Should(Equal("admin"))
+		})
+
+		It("should take promoted fields from proto", func() {
+			a := NewFactory(Account{Base: Base{ID: "42"}}, Use("bob").For("Name")).MustCreate().(*Account)
+			Ω(a.ID).Should(Equal("42"))
+			Ω(a.Name).Should(Equal("bob"))
+		})
+
+		It("should return error on promoted field of unexported embedded pointer", func() {
+			_, err := NewFactoryE(Account{}, WithGenE(NewGenerator("admin"), "UpdatedBy"))
+			Ω(err).Should(MatchError(`field "UpdatedBy" can not be set in Account`))
+		})
+
+		It("should panic with clear error on promoted field of unexported embedded pointer", func() {
+			Ω(func() { NewFactory(Account{}, Use("admin").For("UpdatedBy")) }).Should(PanicWithError(
+				errors.New(`field "UpdatedBy" can not be set in Account`),
+			))
+			Ω(func() { NewFactory(Account{}, Use("admin").ForIndex([]int{2, 0})) }).Should(PanicWithError(
+				errors.New(`field "audit" can not be set in Account`),
+			))
+		})
+
+		It("should allocate nil embedded pointers on every instance", func() {
+			f := NewFactory(Account{}, Use("admin").ForIndex([]int{1, 0}))
+			a1, a2 := f.MustCreate().(*Account), f.MustCreate().(*Account)
+			Ω(a1.CreatedBy).Should(Equal("admin"))
+			Ω(a1.Audit).ShouldNot(BeIdenticalTo(a2.Audit))
+		})
+
+		It("should copy promoted field of nil embedded pointer as zero value", func() {
+			a := NewFactory(Account{}, Use(CopyField("CreatedBy")).For("Name")).MustCreate().(*Account)
+			Ω(a.Name).Should(BeEmpty())
+		})
+	})
+
+	Describe("CreateN", func() {
+		It("should create n instances", func() {
+			users, err := userFact.CreateN(3)
+			Ω(err).Should(BeNil())
+			Ω(users).Should(HaveLen(3))
+			for _, i := range users {
+				u, ok := i.(*User)
+				Ω(ok).Should(BeTrue())
+				Ω(u.Username).Should(BelongTo("john", "james", "bob", "paul"))
+			}
+		})
+
+		It("should keep sequence generators advancing across the batch", func() {
+			users := userFact.MustCreateN(3, Use(SeqSelect("a", "b", "c")).For("Username"))
+			Ω(users[0].(*User).Username).Should(Equal("a"))
+			Ω(users[1].(*User).Username).Should(Equal("b"))
+			Ω(users[2].(*User).Username).Should(Equal("c"))
+		})
+
+		It("should expose instance index to generators", func() {
+			index := Use(func(ctx Ctx) (interface{}, error) { return ctx.Index, nil })
+			users := userFact.MustCreateN(3, index.For("Age"))
+			for i, u := range users {
+				Ω(u.(*User).Age).Should(Equal(i))
+			}
+
+			var list []User
+			Ω(userFact.FillSlice(&list, 2, index.For("Age"))).Should(Succeed())
+			Ω(list[1].Age).Should(Equal(1))
+
+			Ω(userFact.MustCreate(index.For("Age")).(*User).Age).Should(Equal(0))
+		})
+
+		It("should return error if any instance fails", func() {
+			users, err := userFact.CreateN(
+				3,
+				Use(func(ctx Ctx) (interface{}, error) {
+					return nil, errors.New("boom")
+				}).For("FirstName"),
+			)
+			Ω(err).Should(MatchError(`field "FirstName": boom`))
+			Ω(users).Should(BeNil())
+		})
+
+		It("should return error on negative number of instances", func() {
+			users, err := userFact.CreateN(-1)
+			Ω(err).Should(MatchError("expect non-negative number of instances but was: -1"))
+			Ω(users).Should(BeNil())
+
+			_, err = userFact.CreateN(-1, Use("a").For("Comment"))
+			Ω(err).Should(MatchError("expect non-negative number of instances but was: -1"))
+			Ω(func() { userFact.MustCreateN(-1) }).Should(Panic())
+
+			users, err = userFact.CreateN(0)
+			Ω(err).Should(BeNil())
+			Ω(users).Should(BeEmpty())
+		})
+	})
+
+	Describe("CreateBatch", func() {
+		It("should apply overrides of instance index", func() {
+			users, err := userFact.CreateBatch(10, func(i int) []FieldGenFunc {
+				if i < 3 {
+					return []FieldGenFunc{Use("admin").For("Comment")}
+				}
+				return nil
+			})
+			Ω(err).Should(BeNil())
+			Ω(users).Should(HaveLen(10))
+			for i, u := range users {
+				if i < 3 {
+					Ω(u.(*User).Comment).Should(Equal("admin"))
+				} else {
+					Ω(u.(*User).Comment).Should(BeEmpty())
+				}
+			}
+		})
+
+		It("should keep generators of factory advancing across the batch", func() {
+			f := userFact.Derive(Use(SeqSelect("a", "b", "c")).For("Username"))
+			users, err := f.CreateBatch(3, func(i int) []FieldGenFunc {
+				return []FieldGenFunc{Use(i * 10).For("Age")}
+			})
+			Ω(err).Should(BeNil())
+			for i, u := range users {
+				Ω(u.(*User).Username).Should(Equal([]string{"a", "b", "c"}[i]))
+				Ω(u.(*User).Age).Should(Equal(i * 10))
+			}
+		})
+
+		It("should return instances created before failure", func() {
+			users, err := userFact.CreateBatch(5, func(i int) []FieldGenFunc {
+				if i == 2 {
+					return []FieldGenFunc{Use(func() (string, error) { return "", errors.New("boom") }).For("Comment")}
+				}
+				return nil
+			})
+			Ω(err).Should(MatchError(`field "Comment": boom`))
+			Ω(users).Should(HaveLen(2))
+		})
+
+		It("should return error on negative number of instances", func() {
+			users, err := userFact.CreateBatch(-1, func(i int) []FieldGenFunc { return nil })
+			Ω(err).Should(MatchError("expect non-negative number of instances but was: -1"))
+			Ω(users).Should(BeNil())
+		})
+	})
+
+	Describe("FillSlice", func() {
+		It("should fill slice of values", func() {
+			var users []User
+			Ω(userFact.FillSlice(&users, 3)).Should(Succeed())
+			Ω(users).Should(HaveLen(3))
+			Ω(users[0].Email).Should(Equal(users[0].Username + "@6river.com"))
+		})
+
+		It("should fill slice of pointers with overrides", func() {
+			var users []*User
+			Ω(userFact.FillSlice(&users, 2, Use("jane").For("Username"))).Should(Succeed())
+			Ω(users).Should(HaveLen(2))
+			Ω(users[1].Username).Should(Equal("jane"))
+		})
+
+		It("should return error if dst is not a pointer to slice", func() {
+			var users []User
+			Ω(userFact.FillSlice(users, 1)).Should(MatchError("expect pointer to slice but was: []factory_test.User"))
+		})
+
+		It("should return error if dst element type does not match", func() {
+			var addrs []Address
+			Ω(userFact.FillSlice(&addrs, 1)).Should(MatchError("expect pointer to slice of factory_test.User but was: *[]factory_test.Address"))
+		})
+	})
+
+	Describe("CreateSafe", func() {
+		It("should return panic of misconfigured generator as error", func() {
+			u, err := userFact.CreateSafe(Use(1).For("i"))
+			Ω(err).Should(MatchError(`factory_test.User factory failed: field "i" can not be set in User`))
+			Ω(u).Should(BeNil())
+
+			_, err = userFact.CreateSafe(Use(func(ctx Ctx) (interface{}, error) {
+				panic("bad arguments")
+			}).For("Comment"))
+			Ω(err).Should(MatchError("factory_test.User factory failed: bad arguments"))
+		})
+
+		It("should create instance", func() {
+			u, err := userFact.CreateSafe()
+			Ω(err).Should(BeNil())
+			Ω(u.(*User).Username).ShouldNot(BeEmpty())
+		})
+
+		It("should panic on runtime errors", func() {
+			Ω(func() {
+				userFact.CreateSafe(Use(func(ctx Ctx) (interface{}, error) {
+					var c *Customer
+					return c.Name, nil
+				}).For("Comment"))
+			}).Should(Panic())
+		})
+	})
+
 	Describe("MustCreate and MustSetFields", func() {
 		It("should panic on error", func() {
 			Ω(func() {
@@ -213,7 +1533,7 @@ var _ = Describe("Factory", func() {
 						return nil, errors.New("boom")
 					}).For("FirstName"),
 				)
-			}).Should(PanicWithError(errors.New("boom")))
+			}).Should(PanicWithError(&FieldError{Field: "FirstName", Err: errors.New("boom")}))
 
 			Ω(func() {
 				var u User
@@ -223,7 +1543,7 @@ var _ = Describe("Factory", func() {
 						return nil, errors.New("boom")
 					}).For("FirstName"),
 				)
-			}).Should(PanicWithError(errors.New("boom")))
+			}).Should(PanicWithError(&FieldError{Field: "FirstName", Err: errors.New("boom")}))
 
 		})
 	})
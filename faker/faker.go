@@ -0,0 +1,74 @@
+// Package faker provides generators of fake personal data like names, emails and phones.
+// The values are drawn from the factory random source, so a factory with seeded source
+// made by Factory.WithRand yields the same data on every run.
+package faker
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	factory "github.com/kolach/go-factory"
+)
+
+var firstNames = []string{
+	"James", "John", "Robert", "Michael", "William", "David", "Richard", "Joseph", "Thomas", "Charles",
+	"Mary", "Patricia", "Jennifer", "Linda", "Elizabeth", "Barbara", "Susan", "Jessica", "Sarah", "Karen",
+}
+
+var lastNames = []string{
+	"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez",
+	"Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin",
+}
+
+var domains = []string{"example.com", "example.org", "example.net", "mail.test"}
+
+// intn returns random integer in [0, n) drawn from the random source of the factory in context
+// or from the global one if generator is called without factory
+func intn(ctx factory.Ctx, n int) int {
+	if ctx.Rand != nil {
+		return ctx.Rand.Intn(n)
+	}
+	return rand.Intn(n)
+}
+
+// pick randomly picks one of options
+func pick(ctx factory.Ctx, options []string) string {
+	return options[intn(ctx, len(options))]
+}
+
+// FirstName generates first names like "Mary"
+func FirstName() factory.GeneratorFunc {
+	return func(ctx factory.Ctx) (interface{}, error) {
+		return pick(ctx, firstNames), nil
+	}
+}
+
+// LastName generates last names like "Smith"
+func LastName() factory.GeneratorFunc {
+	return func(ctx factory.Ctx) (interface{}, error) {
+		return pick(ctx, lastNames), nil
+	}
+}
+
+// FullName generates first and last names separated by space like "Mary Smith"
+func FullName() factory.GeneratorFunc {
+	return func(ctx factory.Ctx) (interface{}, error) {
+		return pick(ctx, firstNames) + " " + pick(ctx, lastNames), nil
+	}
+}
+
+// Email generates email addresses at reserved domains like "mary.smith42@example.com"
+func Email() factory.GeneratorFunc {
+	return func(ctx factory.Ctx) (interface{}, error) {
+		user := strings.ToLower(pick(ctx, firstNames) + "." + pick(ctx, lastNames))
+		return fmt.Sprintf("%s%d@%s", user, intn(ctx, 100), pick(ctx, domains)), nil
+	}
+}
+
+// Phone generates US phone numbers in fictional 555-01XX range like "+1 (212) 555-0142"
+func Phone() factory.GeneratorFunc {
+	return func(ctx factory.Ctx) (interface{}, error) {
+		return fmt.Sprintf("+1 (%d) 555-01%02d", 200+intn(ctx, 800), intn(ctx, 100)), nil
+	}
+}
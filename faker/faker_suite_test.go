@@ -0,0 +1,13 @@
+package faker_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestFaker(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Faker Suite")
+}
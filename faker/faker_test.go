@@ -0,0 +1,52 @@
+package faker_test
+
+import (
+	"math/rand"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	factory "github.com/kolach/go-factory"
+	. "github.com/kolach/go-factory/faker"
+)
+
+type Person struct {
+	FirstName string
+	LastName  string
+	FullName  string
+	Email     string
+	Phone     string
+}
+
+var _ = Describe("faker", func() {
+	personFact := factory.NewFactory(
+		Person{},
+		factory.Use(FirstName()).For("FirstName"),
+		factory.Use(LastName()).For("LastName"),
+		factory.Use(FullName()).For("FullName"),
+		factory.Use(Email()).For("Email"),
+		factory.Use(Phone()).For("Phone"),
+	)
+
+	It("should generate fake data", func() {
+		p := personFact.MustCreate().(*Person)
+		Ω(p.FirstName).Should(MatchRegexp(`^[A-Z][a-z]+$`))
+		Ω(p.LastName).Should(MatchRegexp(`^[A-Z][a-z]+$`))
+		Ω(p.FullName).Should(MatchRegexp(`^[A-Z][a-z]+ [A-Z][a-z]+$`))
+		Ω(p.Email).Should(MatchRegexp(`^[a-z]+\.[a-z]+\d*@[a-z]+\.[a-z]+$`))
+		Ω(p.Phone).Should(MatchRegexp(`^\+1 \(\d{3}\) 555-01\d{2}$`))
+	})
+
+	It("should draw from factory random source", func() {
+		create := func() *Person {
+			return personFact.WithRand(rand.New(rand.NewSource(42))).MustCreate().(*Person)
+		}
+		Ω(create()).Should(Equal(create()))
+	})
+
+	It("should work without factory", func() {
+		name, err := FirstName()(factory.Ctx{})
+		Ω(err).Should(BeNil())
+		Ω(name).ShouldNot(BeEmpty())
+	})
+})
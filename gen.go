@@ -1,13 +1,39 @@
 package factory
 
 import (
+	"errors"
 	"fmt"
+	"math/rand"
 	"reflect"
+	"regexp"
+	"sort"
+	"strings"
 	"sync/atomic"
+)
+
+// genKind is the kind of field generator, it's used for diagnostics only
+type genKind int
 
-	randomdata "github.com/Pallinder/go-randomdata"
+const (
+	kindGenerator genKind = iota // generator function
+	kindValue                    // static value
+	kindSelect                   // random select from list of values
+	kindFunc                     // adapted arbitrary function
+	kindFactory                  // sub-factory
+	kindChan                     // channel
+	kindProto                    // proto object field value
+	kindTag                      // struct tag directive
+	kindCopy                     // copy of another field value
+	kindDefault                  // default of field kind, see Factory.WithDefaults
 )
 
+var genKindNames = [...]string{"generator", "value", "select", "func", "factory", "channel", "proto", "tag", "copy", "default"}
+
+// String implements fmt.Stringer
+func (k genKind) String() string {
+	return genKindNames[k]
+}
+
 // adaptValue converts/adapts passed value into value generator
 func adaptValue(i interface{}) GeneratorFunc {
 	return func(Ctx) (interface{}, error) {
@@ -15,62 +41,170 @@ func adaptValue(i interface{}) GeneratorFunc {
 	}
 }
 
+// adaptChan makes generator receiving values from channel ch. It blocks until a value is sent
+// or the context of instance creation is done, and returns an error once the channel is closed and drained.
+func adaptChan(ch reflect.Value) GeneratorFunc {
+	return func(ctx Ctx) (interface{}, error) {
+		cases := []reflect.SelectCase{{Dir: reflect.SelectRecv, Chan: ch}}
+		if ctx.Context != nil {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Context.Done())})
+		}
+
+		chosen, val, ok := reflect.Select(cases)
+		if chosen > 0 {
+			return nil, ctx.Context.Err()
+		}
+		if !ok {
+			return nil, fieldError(ctx.Field, errors.New("channel is closed"))
+		}
+		return val.Interface(), nil
+	}
+}
+
 var errorInterface = reflect.TypeOf((*error)(nil)).Elem()
 
-// adaptFunc tries to adapt arbitrary function to be used as generator
-func adaptFunc(f interface{}, args ...interface{}) GeneratorFunc {
+// adaptFunc tries to adapt arbitrary function to be used as generator.
+// It returns an error if the function can't be called with args or returns unexpected values.
+func adaptFunc(f interface{}, args ...interface{}) (GeneratorFunc, error) {
 	val := reflect.ValueOf(f)
 	typ := reflect.TypeOf(f)
 
 	// check input argumrnts
 	if !typ.IsVariadic() && typ.NumIn() != len(args) {
-		panic(fmt.Errorf("not enough input arguments to make a function call. Expected: %d, was: %d",
-			typ.NumIn(), len(args)))
+		return nil, fmt.Errorf("not enough input arguments to make a function call. Expected: %d, was: %d",
+			typ.NumIn(), len(args))
+	}
+	if typ.IsVariadic() && len(args) < typ.NumIn()-1 {
+		return nil, fmt.Errorf("not enough input arguments to make a function call. Expected at least: %d, was: %d",
+			typ.NumIn()-1, len(args))
 	}
 
 	// check function signature. Perimted number is 1 or 2
 	if typ.NumOut() == 0 || typ.NumOut() > 2 {
-		panic(fmt.Errorf("expect function to return 1 or 2 values but was: %d", typ.NumOut()))
+		return nil, fmt.Errorf("expect function to return 1 or 2 values but was: %d", typ.NumOut())
 	}
 
 	// check second output parameter implements error interface
 	if typ.NumOut() == 2 && !typ.Out(1).Implements(errorInterface) {
-		panic(fmt.Errorf("expect second returned type implement error but found: %+v", typ.Out(1)))
+		return nil, fmt.Errorf("expect second returned type implement error but found: %+v", typ.Out(1))
 	}
 
-	// prepare input arguments
+	// the slice passed as the last argument fills in the variadic parameter as is like nums...
+	last := len(args) - 1
+	spread := typ.IsVariadic() && len(args) == typ.NumIn() && args[last] != nil &&
+		reflect.TypeOf(args[last]).AssignableTo(typ.In(last))
+	call := val.Call
+	if spread {
+		call = val.CallSlice
+	}
+
+	// prepare input arguments, the ones beyond fixed parameters are packed into the variadic one
 	in := make([]reflect.Value, len(args))
 	for i, arg := range args {
-		in[i] = reflect.ValueOf(arg)
+		pTyp := argType(typ, i)
+		if spread && i == last {
+			pTyp = typ.In(i)
+		}
+		if arg == nil {
+			in[i] = reflect.Zero(pTyp)
+			continue
+		}
+		if in[i] = reflect.ValueOf(arg); !in[i].Type().AssignableTo(pTyp) {
+			return nil, fmt.Errorf("expect argument %d of type %s but was: %s", i, pTyp, in[i].Type())
+		}
 	}
 
 	return func(Ctx) (interface{}, error) {
-		r := val.Call(in)
+		r := call(in)
 		if len(r) == 1 || r[1].IsNil() {
 			return r[0].Interface(), nil
 		}
 		return r[0].Interface(), r[1].Interface().(error)
+	}, nil
+}
+
+// argType returns the type of parameter of function type typ the i-th argument is passed to,
+// that is the element type of variadic parameter for the arguments beyond fixed parameters.
+func argType(typ reflect.Type, i int) reflect.Type {
+	if typ.IsVariadic() && i >= typ.NumIn()-1 {
+		return typ.In(typ.NumIn() - 1).Elem()
 	}
+	return typ.In(i)
 }
 
-// Seq returns function that sequentially generates integers in interval [0, max)
+// Seq returns function that sequentially generates integers in interval [0, max).
+// It is safe to use concurrently.
 func Seq(max int) func() int {
+	n := uint64(0)
+	return func() int {
+		// the counter is kept in [0, max), so it never overflows and always wraps with the sequence
+		for {
+			x := atomic.LoadUint64(&n)
+			if atomic.CompareAndSwapUint64(&n, x, (x+1)%uint64(max)) {
+				return int(x)
+			}
+		}
+	}
+}
+
+// SeqFrom returns function that generates unbounded monotonically increasing integers
+// start, start+step, start+2*step, ... It is safe to use concurrently.
+func SeqFrom(start, step int) func() int {
 	n := int64(0)
 	return func() int {
-		x := int(n)
-		atomic.AddInt64(&n, 1)
-		return x % max
+		x := atomic.AddInt64(&n, 1) - 1
+		return start + int(x)*step
+	}
+}
+
+// Sequence returns generator that calls fn with counter incremented on each generated value
+// starting from 1, for example to make "user1", "user2", ... It is safe to use concurrently.
+// The counter starts over on Factory.Reset.
+func Sequence(fn func(n int) interface{}) GeneratorFunc {
+	c := &counter{}
+	return func(ctx Ctx) (interface{}, error) {
+		track(ctx, c)
+		return fn(int(c.next() + 1)), nil
 	}
 }
 
-// Rnd returns function that randomly enerates integers in interval [0, max)
+// badVerb matches the fmt error markers like %!d(string=x), %!d(MISSING) and %!(EXTRA int=1)
+var badVerb = regexp.MustCompile(`%!\w?\(`)
+
+// Format returns generator that formats values of generators gens according to pattern,
+// for example Format("ORD-%06d", NewGenerator(SeqFrom(1, 1))) makes "ORD-000001", "ORD-000002", ...
+// It returns an error if the values do not match the verbs of pattern.
+func Format(pattern string, gens ...GeneratorFunc) GeneratorFunc {
+	return func(ctx Ctx) (interface{}, error) {
+		values := make([]interface{}, len(gens))
+		for i, g := range gens {
+			v, err := g(ctx)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+
+		s := fmt.Sprintf(pattern, values...)
+		if badVerb.MatchString(s) {
+			return nil, fieldError(ctx.Field, fmt.Errorf("bad format %q: %s", pattern, s))
+		}
+		return s, nil
+	}
+}
+
+// Rnd returns function that randomly generates integers in interval [0, max) drawn from
+// the global random source, so it ignores the one of Factory.WithRand. In seeded factories
+// use IntRange(0, max) for the values of fields and RndSelect instead of Select(Rnd, ...).
 func Rnd(max int) func() int {
 	return func() int {
-		return randomdata.Number(max)
+		return globalRand.Intn(max)
 	}
 }
 
-// Select picks a value from options
+// Select picks a value from options with the index generated by f(len(options)), like Select(Seq, ...)
+// or Select(Rnd, ...). The index function has no access to the factory, so to draw random picks
+// from the factory random source, see Factory.WithRand, use RndSelect.
 func Select(f func(int) func() int, options ...interface{}) GeneratorFunc {
 	g := f(len(options))
 	return func(Ctx) (interface{}, error) {
@@ -78,24 +212,579 @@ func Select(f func(int) func() int, options ...interface{}) GeneratorFunc {
 	}
 }
 
-// SeqSelect = Select(Seq, options...)
+// SeqSelect sequentially picks a value from options like Select(Seq, options...)
+// but starts over from the first option on Factory.Reset.
 func SeqSelect(options ...interface{}) GeneratorFunc {
-	return Select(Seq, options...)
+	c := &counter{}
+	return func(ctx Ctx) (interface{}, error) {
+		track(ctx, c)
+		return options[c.next()%uint64(len(options))], nil
+	}
 }
 
-// RndSelect = Select(Rnd, options...)
+// RndSelect randomly picks a value from options. The value is drawn from
+// the factory random source if one is set, see Factory.WithRand.
 func RndSelect(options ...interface{}) GeneratorFunc {
-	return Select(Rnd, options...)
+	return func(ctx Ctx) (interface{}, error) {
+		return options[randIntn(ctx, len(options))], nil
+	}
+}
+
+// Pick randomly picks an element of slice or array like RndSelect over its elements,
+// for example Pick([]string{"a", "b", "c"}). Note that Use of slice value assigns the slice itself.
+// It panics if the value is not a slice or array or has no elements.
+func Pick(slice interface{}) GeneratorFunc {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		panic(fmt.Errorf("expect slice or array to pick from but was: %T", slice))
+	}
+	if v.Len() == 0 {
+		panic(errors.New("expect at least one element to pick from"))
+	}
+	options := make([]interface{}, v.Len())
+	for i := range options {
+		options[i] = v.Index(i).Interface()
+	}
+	return RndSelect(options...)
+}
+
+// Enum is the generator of enum values made by EnumOf
+type Enum struct {
+	typ    reflect.Type
+	values []interface{}
+}
+
+// EnumOf randomly picks one of enum values like RndSelect, but the values must be of the same type
+// and the field must be of that type or an interface, so the values of other enum or plain integers
+// are not mixed in. It panics on values of different types. Use checks the type of the field when
+// the generator is bound to it with For, so the factory constructor panics on field of other type.
+// Wrap it with NewGenerator to pass to other generators, then the field is checked on generation.
+func EnumOf(values ...interface{}) Enum {
+	if len(values) == 0 {
+		panic(errors.New("expect at least one enum value to select from"))
+	}
+	typ := reflect.TypeOf(values[0])
+	for _, v := range values[1:] {
+		if reflect.TypeOf(v) != typ {
+			panic(fmt.Errorf("expect enum values of type %s but was: %T", typ, v))
+		}
+	}
+	return Enum{typ: typ, values: values}
+}
+
+// checkField returns an error if the field of type typ can't hold the enum values
+func (e Enum) checkField(typ reflect.Type) error {
+	if typ != e.typ && !(typ.Kind() == reflect.Interface && e.typ.Implements(typ)) {
+		return fmt.Errorf("expect field of enum type %s but was: %s", e.typ, typ)
+	}
+	return nil
+}
+
+// generator makes generator of enum values checking the field type on every call
+func (e Enum) generator() GeneratorFunc {
+	return func(ctx Ctx) (interface{}, error) {
+		if ctx.Instance != nil {
+			typ, err := fieldType(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if err := e.checkField(typ); err != nil {
+				return nil, fieldError(ctx.Field, err)
+			}
+		}
+		return e.values[randIntn(ctx, len(e.values))], nil
+	}
+}
+
+// IntRange randomly generates integers in interval [min, max)
+func IntRange(min, max int) GeneratorFunc {
+	if min >= max {
+		panic(fmt.Errorf("expect min to be less than max but was: [%d, %d)", min, max))
+	}
+	return func(ctx Ctx) (interface{}, error) {
+		return min + randIntn(ctx, max-min), nil
+	}
+}
+
+// FloatRange randomly generates floats in interval [min, max)
+func FloatRange(min, max float64) GeneratorFunc {
+	if min >= max {
+		panic(fmt.Errorf("expect min to be less than max but was: [%v, %v)", min, max))
+	}
+	return func(ctx Ctx) (interface{}, error) {
+		return min + randFloat64(ctx)*(max-min), nil
+	}
+}
+
+// Maybe delegates to generator g with probability p and otherwise returns nil
+// that leaves the field at zero value.
+func Maybe(p float64, g GeneratorFunc) GeneratorFunc {
+	if p < 0 || p > 1 {
+		panic(fmt.Errorf("expect probability to be in [0, 1] but was: %v", p))
+	}
+	return func(ctx Ctx) (interface{}, error) {
+		if randFloat64(ctx) < p {
+			return g(ctx)
+		}
+		return nil, nil
+	}
+}
+
+// Nullable is like Maybe but for the fields representing nullable columns. With probability p it
+// sets the value of generator g to the fields of sql.Null* types, like sql.NullString, marking them Valid,
+// and to the pointer fields allocating the pointer. Otherwise the Null* fields are left not Valid and
+// the pointer fields nil. It returns an error for the fields of other types.
+func Nullable(p float64, g GeneratorFunc) GeneratorFunc {
+	if p < 0 || p > 1 {
+		panic(fmt.Errorf("expect probability to be in [0, 1] but was: %v", p))
+	}
+	return func(ctx Ctx) (interface{}, error) {
+		if ctx.Instance == nil {
+			return Maybe(p, g)(ctx)
+		}
+		typ, err := fieldType(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		inner, ok := nullValueField(typ)
+		if !ok && typ.Kind() != reflect.Ptr {
+			return nil, fieldError(ctx.Field, fmt.Errorf("expect sql.Null* or pointer field but was: %s", typ))
+		}
+		if randFloat64(ctx) >= p {
+			return reflect.Zero(typ).Interface(), nil
+		}
+
+		if !ok {
+			val, err := generateValue(ctx, g, typ.Elem())
+			if err != nil {
+				return nil, err
+			}
+			ptr := reflect.New(typ.Elem())
+			ptr.Elem().Set(val)
+			return ptr.Interface(), nil
+		}
+
+		val, err := generateValue(ctx, g, inner.Type)
+		if err != nil {
+			return nil, err
+		}
+		null := reflect.New(typ).Elem()
+		null.FieldByIndex(inner.Index).Set(val)
+		null.FieldByName("Valid").SetBool(true)
+		return null.Interface(), nil
+	}
+}
+
+// nullValueField returns the value field of sql.Null* like struct type typ, that is
+// a struct of Valid bool field and one more exported field holding the value.
+func nullValueField(typ reflect.Type) (reflect.StructField, bool) {
+	if typ.Kind() != reflect.Struct || typ.NumField() != 2 {
+		return reflect.StructField{}, false
+	}
+	if valid, ok := typ.FieldByName("Valid"); !ok || valid.Type.Kind() != reflect.Bool {
+		return reflect.StructField{}, false
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if f := typ.Field(i); f.Name != "Valid" && f.PkgPath == "" {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// Retry invokes generator g again on error up to attempts times in total
+// and returns the last error if all the attempts fail.
+func Retry(g GeneratorFunc, attempts int) GeneratorFunc {
+	return RetryIf(g, attempts, func(error) bool { return true })
+}
+
+// RetryIf is like Retry but invokes generator g again only if retryable returns true for the error.
+// The retryable function can also sleep before the next attempt to back off.
+func RetryIf(g GeneratorFunc, attempts int, retryable func(err error) bool) GeneratorFunc {
+	if attempts < 1 {
+		panic(fmt.Errorf("expect at least 1 attempt but was: %d", attempts))
+	}
+	return func(ctx Ctx) (interface{}, error) {
+		for i := 1; ; i++ {
+			val, err := g(ctx)
+			if err == nil || i == attempts || !retryable(err) {
+				return val, err
+			}
+		}
+	}
+}
+
+// If delegates to generator g if pred holds and otherwise returns nil that leaves
+// the field at zero value. The predicate can inspect the fields of ctx.Instance that are
+// already generated, so register the generator after them or use DependsOn.
+func If(pred func(ctx Ctx) bool, g GeneratorFunc) GeneratorFunc {
+	return func(ctx Ctx) (interface{}, error) {
+		if pred(ctx) {
+			return g(ctx)
+		}
+		return nil, nil
+	}
+}
+
+// SelectFactory returns generator that creates the field value with the sub-factory chosen by
+// choose, for example depending on the fields of ctx.Instance that are already generated. Like
+// with If the fields choose reads must be generated first, see DependsOn. The sub-factory
+// inherits the call depth of the factory it is used in. Nil sub-factory makes it return an error.
+func SelectFactory(choose func(ctx Ctx) *Factory) GeneratorFunc {
+	return func(ctx Ctx) (interface{}, error) {
+		f := choose(ctx)
+		if f == nil {
+			return nil, fieldError(ctx.Field, errors.New("nil sub-factory"))
+		}
+		return subFactory(f, ctx).Create()
+	}
 }
 
-// NewGenerator makes a field generator function
+// CopyField returns generator that copies the current value of the field of instance addressed
+// by name, which can be a dotted path. The field must be generated first, so register the generator
+// after it or use DependsOn. It returns an error if the field is not found.
+func CopyField(name string) GeneratorFunc {
+	return func(ctx Ctx) (interface{}, error) {
+		return fieldValue(ctx, name)
+	}
+}
+
+// Map returns generator that post-processes the value of generator g with transform function,
+// for example to format or upper-case it. Errors of g are returned without calling transform.
+func Map(g GeneratorFunc, transform func(interface{}) (interface{}, error)) GeneratorFunc {
+	return func(ctx Ctx) (interface{}, error) {
+		val, err := g(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return transform(val)
+	}
+}
+
+// OneOf randomly picks one of generators and runs it. The generator is drawn
+// from the factory random source if one is set, see Factory.WithRand.
+func OneOf(gens ...GeneratorFunc) GeneratorFunc {
+	if len(gens) == 0 {
+		panic(errors.New("expect at least one generator to select from"))
+	}
+	return func(ctx Ctx) (interface{}, error) {
+		return gens[randIntn(ctx, len(gens))](ctx)
+	}
+}
+
+// WeightedOption is an option of WeightedSelect with its relative weight
+type WeightedOption struct {
+	Value  interface{}
+	Weight float64
+}
+
+// WeightedSelect randomly picks a value from options with probability
+// proportional to option weight. Weights do not need to sum to 1.
+func WeightedSelect(options ...WeightedOption) GeneratorFunc {
+	if len(options) == 0 {
+		panic(errors.New("expect at least one option to select from"))
+	}
+
+	// build cumulative distribution
+	cdf := make([]float64, len(options))
+	total := 0.0
+	for i, opt := range options {
+		if opt.Weight <= 0 {
+			panic(fmt.Errorf("expect option weight to be positive but was: %v", opt.Weight))
+		}
+		total += opt.Weight
+		cdf[i] = total
+	}
+
+	return func(ctx Ctx) (interface{}, error) {
+		x := randFloat64(ctx) * total
+		i := sort.SearchFloat64s(cdf, x)
+		if i < len(cdf) && cdf[i] == x {
+			// x belongs to the next option interval
+			i++
+		}
+		if i == len(cdf) {
+			i--
+		}
+		return options[i].Value, nil
+	}
+}
+
+// SliceOf makes a slice of instances created by factory f. The count is either a fixed int
+// or a function like Rnd(5) that returns the number of instances to create. The slice type is
+// inferred from the field so both []T and []*T are supported. Sub-factory inherits the call depth
+// of the factory it is used in, so recursion guards keep working.
+func SliceOf(f *Factory, count interface{}) GeneratorFunc {
+	n := countFunc(count)
+	return func(ctx Ctx) (interface{}, error) {
+		typ, err := fieldType(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if typ.Kind() != reflect.Slice {
+			return nil, fieldError(ctx.Field, errors.New("not a slice"))
+		}
+
+		elemType := typ.Elem()
+		if elemType != f.typ && elemType != reflect.PtrTo(f.typ) {
+			return nil, fieldError(ctx.Field, fmt.Errorf("%s can not hold %s instances", typ, f.typ))
+		}
+
+		sub := subFactory(f, ctx)
+		size := n()
+		slice := reflect.MakeSlice(typ, 0, size)
+		for i := 0; i < size; i++ {
+			instance, err := sub.Create()
+			if err != nil {
+				return nil, err
+			}
+			val := reflect.ValueOf(instance)
+			if elemType.Kind() != reflect.Ptr {
+				val = val.Elem()
+			}
+			slice = reflect.Append(slice, val)
+		}
+		return slice.Interface(), nil
+	}
+}
+
+// CountedSlice makes a slice of n values produced by elemGen, for example a few random tags.
+// The n is either a fixed int or a function like Rnd(5). The slice type is inferred from the field
+// and the values are adapted to its element type, so a generator of T values can fill []*T field.
+func CountedSlice(n interface{}, elemGen GeneratorFunc) GeneratorFunc {
+	size := countFunc(n)
+	return func(ctx Ctx) (interface{}, error) {
+		typ, err := fieldType(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if typ.Kind() != reflect.Slice {
+			return nil, fieldError(ctx.Field, errors.New("not a slice"))
+		}
+
+		size := size()
+		slice := reflect.MakeSlice(typ, size, size)
+		for i := 0; i < size; i++ {
+			val, err := generateValue(ctx, elemGen, typ.Elem())
+			if err != nil {
+				return nil, err
+			}
+			slice.Index(i).Set(val)
+		}
+		return slice.Interface(), nil
+	}
+}
+
+// mapKeyRetries is the number of times MapOf draws a key again if it's a duplicate
+const mapKeyRetries = 10
+
+// MapOf makes a map of n entries with keys and values produced by keyGen and valueGen.
+// The n is either a fixed int or a function like Rnd(5). The map type is inferred from the field.
+// Duplicate keys are drawn again up to mapKeyRetries times in a row, so the map may end up
+// with less than n entries if the key generator can't produce enough distinct keys.
+func MapOf(n interface{}, keyGen, valueGen GeneratorFunc) GeneratorFunc {
+	size := countFunc(n)
+	return func(ctx Ctx) (interface{}, error) {
+		typ, err := fieldType(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if typ.Kind() != reflect.Map {
+			return nil, fieldError(ctx.Field, errors.New("not a map"))
+		}
+
+		size := size()
+		m := reflect.MakeMapWithSize(typ, size)
+		for retries := 0; m.Len() < size && retries <= mapKeyRetries; {
+			key, err := generateValue(ctx, keyGen, typ.Key())
+			if err != nil {
+				return nil, err
+			}
+
+			if m.MapIndex(key).IsValid() {
+				// duplicate key, draw again
+				retries++
+				continue
+			}
+			retries = 0
+
+			val, err := generateValue(ctx, valueGen, typ.Elem())
+			if err != nil {
+				return nil, err
+			}
+			m.SetMapIndex(key, val)
+		}
+		return m.Interface(), nil
+	}
+}
+
+// generateValue calls generator and adapts the result to type typ
+func generateValue(ctx Ctx, g GeneratorFunc, typ reflect.Type) (reflect.Value, error) {
+	i, err := g(ctx)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	val, err := valueFor(i, typ)
+	if err != nil {
+		return val, fieldError(ctx.Field, err)
+	}
+	return val, nil
+}
+
+// countFunc converts count given as int or func() int to function
+func countFunc(count interface{}) func() int {
+	switch n := count.(type) {
+	case int:
+		return func() int { return n }
+	case func() int:
+		return n
+	default:
+		panic(fmt.Errorf("expect count to be int or func() int but was: %T", count))
+	}
+}
+
+// subFactory makes a copy of factory f that inherits call depth and random source
+// of the factory in context and creates instances nested in the instance in context.
+func subFactory(f *Factory, ctx Ctx) *Factory {
+	track(ctx, f.resets)
+	sub := *f
+	if ctx.Factory != nil {
+		sub.callDepth = ctx.Factory.callDepth
+	}
+	if sub.rand == nil && ctx.Rand != globalRand {
+		sub.rand = ctx.Rand
+	}
+	sub.context = ctx.Context
+	sub.parent = ctx.Instance
+	return &sub
+}
+
+// fieldType returns the type of field the value is being generated for
+func fieldType(ctx Ctx) (reflect.Type, error) {
+	typ := reflect.TypeOf(ctx.Instance)
+	if typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Map {
+		return typ.Elem().Elem(), nil
+	}
+	for _, name := range strings.Split(ctx.Field, ".") {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			return nil, fieldError(ctx.Field, fmt.Errorf("not found in %s", typ))
+		}
+		sField, ok := typ.FieldByName(name)
+		if !ok {
+			return nil, fieldError(ctx.Field, fmt.Errorf("not found in %s", typ))
+		}
+		typ = sField.Type
+	}
+	return typ, nil
+}
+
+// mapValue returns the value of map m with string keys by key or nil if there is no such key
+func mapValue(m reflect.Value, key string) interface{} {
+	val := m.MapIndex(reflect.ValueOf(key).Convert(m.Type().Key()))
+	if !val.IsValid() {
+		return nil
+	}
+	return val.Interface()
+}
+
+// fieldValue returns the current value of the field of instance in context addressed by dotted path.
+// It returns nil if there is a nil pointer to struct on the path.
+func fieldValue(ctx Ctx, path string) (interface{}, error) {
+	val := reflect.ValueOf(ctx.Instance)
+	if val.Kind() == reflect.Ptr && val.Elem().Kind() == reflect.Map {
+		return mapValue(val.Elem(), path), nil
+	}
+	for _, name := range strings.Split(path, ".") {
+		for val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				return nil, nil
+			}
+			val = val.Elem()
+		}
+		if val.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("field %q not found in %s", path, val.Type())
+		}
+		sField, ok := val.Type().FieldByName(name)
+		if !ok || sField.PkgPath != "" {
+			return nil, fmt.Errorf("field %q not found in %s", path, val.Type())
+		}
+		var err error
+		if val, err = val.FieldByIndexErr(sField.Index); err != nil {
+			// nil pointer to embedded struct
+			return nil, nil
+		}
+	}
+	return val.Interface(), nil
+}
+
+// ctxRand returns the context random source or the global one if the context
+// has no random source, for example it's made by hand.
+func ctxRand(ctx Ctx) *rand.Rand {
+	if ctx.Rand != nil {
+		return ctx.Rand
+	}
+	return globalRand
+}
+
+// randIntn returns random integer in interval [0, n) drawn from the context random source
+func randIntn(ctx Ctx, n int) int {
+	return ctxRand(ctx).Intn(n)
+}
+
+// randInt63n returns random int64 in interval [0, n) drawn from the context random source
+func randInt63n(ctx Ctx, n int64) int64 {
+	return ctxRand(ctx).Int63n(n)
+}
+
+// randFloat64 returns random float in interval [0.0, 1.0) drawn from the context random source
+func randFloat64(ctx Ctx) float64 {
+	return ctxRand(ctx).Float64()
+}
+
+// NewGenerator makes a field generator function. It panics if the function
+// can't be adapted to generator, see NewGeneratorE.
 func NewGenerator(i interface{}, args ...interface{}) GeneratorFunc {
+	g, err := NewGeneratorE(i, args...)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// NewGeneratorE is like NewGenerator but returns an error instead of panic if the function
+// can't be called with args or returns unexpected values, so the generators made from
+// configuration can be reported gracefully.
+func NewGeneratorE(i interface{}, args ...interface{}) (GeneratorFunc, error) {
+	g, _, err := newGeneratorE(i, args...)
+	return g, err
+}
+
+// newGenerator makes a field generator function and tells its kind. It panics on
+// functions that can't be adapted to generator.
+func newGenerator(i interface{}, args ...interface{}) (GeneratorFunc, genKind) {
+	g, kind, err := newGeneratorE(i, args...)
+	if err != nil {
+		panic(err)
+	}
+	return g, kind
+}
+
+// newGeneratorE is newGenerator returning an error instead of panic
+func newGeneratorE(i interface{}, args ...interface{}) (GeneratorFunc, genKind, error) {
 	// for usecases like:
 	// func myGenFunc() GeneratorFunc {
 	//   return func(Ctx) (interface{}, error) { ...  }
 	// }
 	if genFunc, ok := i.(GeneratorFunc); ok {
-		return genFunc
+		return genFunc, kindGenerator, nil
 	}
 
 	// for usecases like:
@@ -109,28 +798,46 @@ func NewGenerator(i interface{}, args ...interface{}) GeneratorFunc {
 	//   Use(func(ctx Ctx) (interface{}, error) { ... }),
 	// )
 	if genFunc, ok := i.(func(Ctx) (interface{}, error)); ok {
-		return genFunc
+		return genFunc, kindGenerator, nil
 	}
 
 	// if i is a factory use Create method
 	if fact, ok := i.(*Factory); ok {
-		return func(Ctx) (interface{}, error) {
-			return fact.Create()
+		if fact == nil {
+			return func(ctx Ctx) (interface{}, error) {
+				return nil, fieldError(ctx.Field, errors.New("nil sub-factory"))
+			}, kindFactory, nil
 		}
+		return func(ctx Ctx) (interface{}, error) {
+			// sub-factory continues at the call depth of the factory it's used in,
+			// so max depth stops recursion passing through other factories
+			return subFactory(fact, ctx).Create()
+		}, kindFactory, nil
+	}
+
+	// if i is an enum, randomly select its values
+	if enum, ok := i.(Enum); ok {
+		return enum.generator(), kindSelect, nil
+	}
+
+	// if i is a channel, receive values from it
+	if v := reflect.ValueOf(i); v.Kind() == reflect.Chan && v.Type().ChanDir()&reflect.RecvDir != 0 {
+		return adaptChan(v), kindChan, nil
 	}
 
 	// if i is a function, use function to generator converter
 	if v := reflect.ValueOf(i); v.Kind() == reflect.Func {
 		// use Func adapter in case i is of Kind Func
-		return adaptFunc(i, args...)
+		g, err := adaptFunc(i, args...)
+		return g, kindFunc, err
 	}
 
 	// if it's just some static value, use value to generator converter
 	if len(args) == 0 {
 		// use static value generator if no other arguments provided
-		return adaptValue(i)
+		return adaptValue(i), kindValue, nil
 	}
 
 	// otherwise make generator function to randomly select from given options
-	return RndSelect(append([]interface{}{i}, args...)...)
+	return RndSelect(append([]interface{}{i}, args...)...), kindSelect, nil
 }
@@ -1,10 +1,21 @@
 package factory_test
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	. "github.com/kolach/go-factory"
+	. "github.com/kolach/gomega-matchers"
 )
 
 var _ = Describe("generators", func() {
@@ -18,5 +29,602 @@ var _ = Describe("generators", func() {
 			Ω(results).To(HaveLen(7))
 			Ω(results).To(Equal([]int{0, 1, 2, 3, 4, 0, 1}))
 		})
+
+		It("should start over after each period", func() {
+			seq := Seq(3)
+			for period := 0; period < 1000; period++ {
+				Ω([]int{seq(), seq(), seq()}).To(Equal([]int{0, 1, 2}))
+			}
+		})
+
+		It("should be safe to use concurrently", func() {
+			seq := Seq(1000)
+			results := make(chan int, 1000)
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						results <- seq()
+					}
+				}()
+			}
+			wg.Wait()
+			close(results)
+
+			seen := map[int]bool{}
+			for n := range results {
+				seen[n] = true
+			}
+			Ω(seen).Should(HaveLen(1000))
+		})
+
+		It("should wrap evenly in concurrent use", func() {
+			seq := Seq(7)
+			counts := make([]int64, 7)
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 700; j++ {
+						atomic.AddInt64(&counts[seq()], 1)
+					}
+				}()
+			}
+			wg.Wait()
+			Ω(counts).Should(Equal([]int64{1000, 1000, 1000, 1000, 1000, 1000, 1000}))
+		})
+	})
+
+	Describe("SeqFrom", func() {
+		It("should generate numbers from start with step", func() {
+			seq := SeqFrom(1000, 10)
+			Ω([]int{seq(), seq(), seq()}).To(Equal([]int{1000, 1010, 1020}))
+		})
+
+		It("should advance across CreateN batch", func() {
+			users := NewFactory(User{}, Use(SeqFrom(1, 1)).For("Age")).MustCreateN(3)
+			Ω(users[0].(*User).Age).Should(Equal(1))
+			Ω(users[1].(*User).Age).Should(Equal(2))
+			Ω(users[2].(*User).Age).Should(Equal(3))
+		})
+	})
+
+	Describe("Sequence", func() {
+		It("should call function with incrementing counter", func() {
+			f := NewFactory(User{}, Use(Sequence(func(n int) interface{} {
+				return fmt.Sprintf("user%d", n)
+			})).For("Username"))
+
+			users := f.MustCreateN(2)
+			Ω(users[0].(*User).Username).Should(Equal("user1"))
+			Ω(users[1].(*User).Username).Should(Equal("user2"))
+			Ω(f.MustCreate().(*User).Username).Should(Equal("user3"))
+		})
+	})
+
+	Describe("Channel", func() {
+		It("should receive values from channel", func() {
+			ch := make(chan string, 2)
+			ch <- "john"
+			ch <- "jane"
+			close(ch)
+
+			f := NewFactory(User{}, Use(ch).For("Username"))
+			Ω(f.MustCreate().(*User).Username).Should(Equal("john"))
+			Ω(f.MustCreate().(*User).Username).Should(Equal("jane"))
+			_, err := f.Create()
+			Ω(err).Should(MatchError(`field "Username": channel is closed`))
+		})
+
+		It("should stop waiting when context is done", func() {
+			c, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			f := NewFactory(User{}, Use((<-chan string)(make(chan string))).For("Username"))
+			_, err := f.CreateCtx(c)
+			Ω(errors.Is(err, context.DeadlineExceeded)).Should(BeTrue())
+		})
+	})
+
+	Describe("Format", func() {
+		It("should format values of generators", func() {
+			gen := Format("ORD-%06d-%s", NewGenerator(SeqFrom(1, 1)), NewGenerator("x"))
+			Ω(gen(Ctx{})).Should(Equal("ORD-000001-x"))
+			Ω(gen(Ctx{})).Should(Equal("ORD-000002-x"))
+		})
+
+		It("should return error if values do not match verbs", func() {
+			for _, gen := range []GeneratorFunc{
+				Format("%d", NewGenerator("x")),
+				Format("%d %d", NewGenerator(1)),
+				Format("%d", NewGenerator(1), NewGenerator(2)),
+			} {
+				_, err := gen(Ctx{Field: "ID"})
+				Ω(err).Should(HaveOccurred())
+			}
+		})
+
+		It("should return error of generator", func() {
+			_, err := Format("%d", func(Ctx) (interface{}, error) { return nil, errors.New("boom") })(Ctx{})
+			Ω(err).Should(MatchError("boom"))
+		})
+	})
+
+	Describe("Pick", func() {
+		It("should pick elements of slice", func() {
+			names := []string{"a", "b", "c"}
+			f := NewFactory(User{}, Use(Pick(names)).For("Username"))
+			seen := map[string]bool{}
+			for i := 0; i < 100; i++ {
+				u := f.MustCreate().(*User)
+				Ω(u.Username).Should(BeElementOf(names))
+				seen[u.Username] = true
+			}
+			Ω(seen).Should(HaveLen(3))
+		})
+
+		It("should pick elements of array", func() {
+			v, err := Pick([2]int{1, 2})(Ctx{})
+			Ω(err).Should(BeNil())
+			Ω(v).Should(BeElementOf(1, 2))
+		})
+
+		It("should panic on values other than slice or non-empty slice", func() {
+			Ω(func() { Pick("abc") }).Should(PanicWithError(errors.New("expect slice or array to pick from but was: string")))
+			Ω(func() { Pick([]int{}) }).Should(PanicWithError(errors.New("expect at least one element to pick from")))
+		})
+	})
+
+	Describe("IntRange and FloatRange", func() {
+		It("should generate numbers in [min, max) interval", func() {
+			ints, floats := IntRange(20, 25), FloatRange(-1.5, 1.5)
+			for i := 0; i < 100; i++ {
+				n, err := ints(Ctx{})
+				Ω(err).Should(BeNil())
+				Ω(n).Should(And(BeNumerically(">=", 20), BeNumerically("<", 25)))
+
+				x, err := floats(Ctx{})
+				Ω(err).Should(BeNil())
+				Ω(x).Should(And(BeNumerically(">=", -1.5), BeNumerically("<", 1.5)))
+			}
+		})
+
+		It("should panic if min is not less than max", func() {
+			Ω(func() { IntRange(5, 5) }).Should(PanicWithError(errors.New("expect min to be less than max but was: [5, 5)")))
+			Ω(func() { FloatRange(2, 1) }).Should(PanicWithError(errors.New("expect min to be less than max but was: [2, 1)")))
+		})
+	})
+
+	Describe("Maybe", func() {
+		It("should generate value with given probability", func() {
+			gen := Maybe(0.3, NewGenerator("Lee"))
+			ctx := Ctx{Rand: rand.New(rand.NewSource(42))}
+			count := 0
+			for i := 0; i < 10000; i++ {
+				v, err := gen(ctx)
+				Ω(err).Should(BeNil())
+				if v != nil {
+					Ω(v).Should(Equal("Lee"))
+					count++
+				}
+			}
+			Ω(count).Should(BeNumerically("~", 3000, 300))
+		})
+
+		It("should always or never generate value on edge probabilities", func() {
+			Ω(Maybe(1, NewGenerator("Lee"))(Ctx{})).Should(Equal("Lee"))
+			Ω(Maybe(0, NewGenerator("Lee"))(Ctx{})).Should(BeNil())
+		})
+
+		It("should panic on invalid probability", func() {
+			Ω(func() { Maybe(1.5, NewGenerator("Lee")) }).Should(PanicWithError(errors.New("expect probability to be in [0, 1] but was: 1.5")))
+		})
+	})
+
+	Describe("Nullable", func() {
+		type Row struct {
+			Name  sql.NullString
+			Age   sql.NullInt64
+			Nick  *string
+			Title string
+		}
+
+		It("should set sql.Null* and pointer fields on probability 1", func() {
+			f := NewFactory(Row{},
+				Use(Nullable(1, NewGenerator("john"))).For("Name", "Nick"),
+				Use(Nullable(1, IntRange(30, 31))).For("Age"),
+			)
+			row := f.MustCreate().(*Row)
+			Ω(row.Name).Should(Equal(sql.NullString{String: "john", Valid: true}))
+			Ω(row.Age).Should(Equal(sql.NullInt64{Int64: 30, Valid: true}))
+			Ω(*row.Nick).Should(Equal("john"))
+		})
+
+		It("should leave fields null on probability 0", func() {
+			f := NewFactory(Row{Nick: new(string)},
+				Use(Nullable(0, NewGenerator("john"))).For("Name", "Nick"),
+			)
+			row := f.MustCreate().(*Row)
+			Ω(row.Name.Valid).Should(BeFalse())
+			Ω(row.Nick).Should(BeNil())
+		})
+
+		It("should set fields with given probability", func() {
+			f := NewFactory(Row{}, Use(Nullable(0.3, NewGenerator("john"))).For("Name")).
+				WithRand(rand.New(rand.NewSource(42)))
+			rows, err := f.CreateN(1000)
+			Ω(err).Should(BeNil())
+			count := 0
+			for _, r := range rows {
+				if r.(*Row).Name.Valid {
+					count++
+				}
+			}
+			Ω(count).Should(BeNumerically("~", 300, 60))
+		})
+
+		It("should return error for other fields", func() {
+			f := NewFactory(Row{}, Use(Nullable(1, NewGenerator("john"))).For("Title"))
+			_, err := f.Create()
+			Ω(err).Should(MatchError(`field "Title": expect sql.Null* or pointer field but was: string`))
+		})
+
+		It("should return error of inner value", func() {
+			f := NewFactory(Row{}, Use(Nullable(1, NewGenerator(true))).For("Age"))
+			_, err := f.Create()
+			Ω(err).Should(MatchError(`field "Age": cannot assign bool to int64`))
+		})
+	})
+
+	Describe("Retry", func() {
+		var (
+			calls int
+			flaky GeneratorFunc
+		)
+
+		BeforeEach(func() {
+			calls = 0
+			flaky = func(Ctx) (interface{}, error) {
+				if calls++; calls < 3 {
+					return nil, fmt.Errorf("failure %d", calls)
+				}
+				return "ok", nil
+			}
+		})
+
+		It("should invoke generator again on error", func() {
+			Ω(Retry(flaky, 3)(Ctx{})).Should(Equal("ok"))
+			Ω(calls).Should(Equal(3))
+		})
+
+		It("should return the last error if all attempts fail", func() {
+			_, err := Retry(flaky, 2)(Ctx{})
+			Ω(err).Should(MatchError("failure 2"))
+		})
+
+		It("should retry only retryable errors", func() {
+			_, err := RetryIf(flaky, 3, func(err error) bool {
+				return err.Error() != "failure 1"
+			})(Ctx{})
+			Ω(err).Should(MatchError("failure 1"))
+			Ω(calls).Should(Equal(1))
+		})
+
+		It("should panic on invalid number of attempts", func() {
+			Ω(func() { Retry(flaky, 0) }).Should(PanicWithError(errors.New("expect at least 1 attempt but was: 0")))
+		})
+	})
+
+	Describe("If", func() {
+		It("should generate value only if predicate holds", func() {
+			premium := func(ctx Ctx) bool { return ctx.Instance.(*User).Username == "premium" }
+			gen := If(premium, NewGenerator("WELCOME10"))
+			Ω(gen(Ctx{Instance: &User{Username: "premium"}})).Should(Equal("WELCOME10"))
+			Ω(gen(Ctx{Instance: &User{Username: "basic"}})).Should(BeNil())
+		})
+
+		It("should read fields generated before", func() {
+			f := NewFactory(
+				User{},
+				Use(If(func(ctx Ctx) bool {
+					return ctx.Instance.(*User).Age >= 18
+				}, NewGenerator(true))).For("Married").DependsOn("Age"),
+				Use(SeqSelect(17, 18)).For("Age"),
+			)
+			Ω(f.MustCreate().(*User).Married).Should(BeFalse())
+			Ω(f.MustCreate().(*User).Married).Should(BeTrue())
+		})
+	})
+
+	Describe("SelectFactory", func() {
+		It("should create value with the factory chosen by instance state", func() {
+			local := NewFactory(Address{}, Use("CDMX").For("City"))
+			abroad := NewFactory(Address{}, Use("Paris").For("City"))
+			f := NewFactory(
+				User{},
+				Use(SelectFactory(func(ctx Ctx) *Factory {
+					if ctx.Instance.(*User).Comment == "MX" {
+						return local
+					}
+					return abroad
+				})).For("Address").DependsOn("Comment"),
+				Use(SeqSelect("MX", "FR")).For("Comment"),
+			)
+			Ω(f.MustCreate().(*User).Address.City).Should(Equal("CDMX"))
+			Ω(f.MustCreate().(*User).Address.City).Should(Equal("Paris"))
+		})
+
+		It("should inherit call depth", func() {
+			var f *Factory
+			f = NewFactory(
+				Node{},
+				Use(SelectFactory(func(Ctx) *Factory { return f })).For("Parent"),
+			).WithMaxDepth(3)
+
+			depth := 0
+			for n := f.MustCreate().(*Node); n != nil; n = n.Parent {
+				depth++
+			}
+			Ω(depth).Should(Equal(3))
+		})
+
+		It("should inherit call depth through sub-factories", func() {
+			var a *Factory
+			b := NewFactory(Node{}, Use(SelectFactory(func(Ctx) *Factory { return a })).For("Parent"))
+			a = NewFactory(Node{}, Use(b).For("Parent")).WithMaxDepth(3)
+
+			depth := 0
+			for n := a.MustCreate().(*Node); n != nil; n = n.Parent {
+				depth++
+			}
+			// b has no max depth of its own, so it makes one more level before a stops
+			Ω(depth).Should(Equal(4))
+		})
+
+		It("should fail on nil factory", func() {
+			f := NewFactory(User{}, Use(SelectFactory(func(Ctx) *Factory { return nil })).For("Address"))
+			_, err := f.Create()
+			Ω(err).Should(MatchError(`field "Address": nil sub-factory`))
+		})
+	})
+
+	Describe("CopyField", func() {
+		It("should copy value of generated field", func() {
+			f := NewFactory(
+				User{},
+				Use(CopyField("Address.City")).For("Comment").DependsOn("Address.City"),
+				Use(SeqSelect("john", "jane")).For("Username"),
+				Use(CopyField("Username")).For("Email").DependsOn("Username"),
+				Use("CDMX").For("Address.City"),
+			)
+			u := f.MustCreate().(*User)
+			Ω(u.Email).Should(Equal("john"))
+			Ω(u.Comment).Should(Equal("CDMX"))
+		})
+
+		It("should return error if field is not found", func() {
+			_, err := CopyField("Nickname")(Ctx{Instance: &User{}})
+			Ω(err).Should(MatchError(`field "Nickname" not found in factory_test.User`))
+		})
+	})
+
+	Describe("Map", func() {
+		upper := func(v interface{}) (interface{}, error) { return strings.ToUpper(v.(string)), nil }
+		greet := func(v interface{}) (interface{}, error) { return "hello " + v.(string), nil }
+
+		It("should transform generated values", func() {
+			gen := Map(Map(SeqSelect("john", "jane"), upper), greet)
+			Ω(gen(Ctx{})).Should(Equal("hello JOHN"))
+			Ω(gen(Ctx{})).Should(Equal("hello JANE"))
+		})
+
+		It("should return error of generator without transforming", func() {
+			called := false
+			gen := Map(func(Ctx) (interface{}, error) { return nil, errors.New("boom") },
+				func(v interface{}) (interface{}, error) { called = true; return v, nil })
+			_, err := gen(Ctx{})
+			Ω(err).Should(MatchError("boom"))
+			Ω(called).Should(BeFalse())
+		})
+
+		It("should return error of transform", func() {
+			gen := Map(Map(NewGenerator("john"), upper), func(interface{}) (interface{}, error) {
+				return nil, errors.New("bad name")
+			})
+			_, err := NewFactory(User{}, Use(gen).For("Username")).Create()
+			Ω(err).Should(MatchError(`field "Username": bad name`))
+		})
+	})
+
+	Describe("OneOf", func() {
+		It("should run one of generators", func() {
+			gen := OneOf(NewGenerator("uuid"), NewGenerator(func() string { return "42" }))
+			results := map[interface{}]bool{}
+			for i := 0; i < 100; i++ {
+				v, err := gen(Ctx{})
+				Ω(err).Should(BeNil())
+				results[v] = true
+			}
+			Ω(results).Should(Equal(map[interface{}]bool{"uuid": true, "42": true}))
+		})
+
+		It("should propagate generator error", func() {
+			gen := OneOf(NewGenerator(func() (string, error) { return "", errors.New("boom") }))
+			_, err := gen(Ctx{})
+			Ω(err).Should(MatchError("boom"))
+		})
+
+		It("should panic on empty generators", func() {
+			Ω(func() { OneOf() }).Should(PanicWithError(errors.New("expect at least one generator to select from")))
+		})
+	})
+
+	Describe("WeightedSelect", func() {
+		It("should select options proportionally to weights", func() {
+			gen := WeightedSelect(
+				WeightedOption{Value: "active", Weight: 8},
+				WeightedOption{Value: "suspended", Weight: 2},
+			)
+			ctx := Ctx{Rand: rand.New(rand.NewSource(42))}
+			counts := map[interface{}]int{}
+			for i := 0; i < 10000; i++ {
+				v, err := gen(ctx)
+				Ω(err).Should(BeNil())
+				counts[v]++
+			}
+			Ω(counts).Should(HaveLen(2))
+			Ω(counts["active"]).Should(BeNumerically("~", 8000, 300))
+		})
+
+		It("should panic on not positive weight", func() {
+			Ω(func() {
+				WeightedSelect(WeightedOption{Value: "active", Weight: 0})
+			}).Should(PanicWithError(errors.New("expect option weight to be positive but was: 0")))
+		})
+
+		It("should panic on empty options", func() {
+			Ω(func() { WeightedSelect() }).Should(PanicWithError(errors.New("expect at least one option to select from")))
+		})
+	})
+
+	Describe("SliceOf", func() {
+		type Street struct {
+			Houses   []Address
+			Pointers []*Address
+			Names    []string
+		}
+
+		var addrFact *Factory
+
+		BeforeEach(func() {
+			addrFact = NewFactory(Address{}, Use(SeqSelect("CDMX", "Cancun")).For("City"))
+		})
+
+		It("should make slices of values and pointers", func() {
+			s := NewFactory(
+				Street{},
+				Use(SliceOf(addrFact, 2)).For("Houses"),
+				Use(SliceOf(addrFact, func() int { return 3 })).For("Pointers"),
+			).MustCreate().(*Street)
+
+			Ω(s.Houses).Should(HaveLen(2))
+			Ω(s.Houses[0].City).Should(Equal("CDMX"))
+			Ω(s.Houses[1].City).Should(Equal("Cancun"))
+			Ω(s.Pointers).Should(HaveLen(3))
+			Ω(s.Pointers[0].City).Should(Equal("CDMX"))
+		})
+
+		It("should inherit call depth", func() {
+			depths := []int{}
+			nodeFact := NewFactory(Node{}, Use(func(ctx Ctx) (interface{}, error) {
+				depths = append(depths, ctx.Factory.CallDepth())
+				return "leaf", nil
+			}).For("Name"))
+
+			root := NewFactory(Node{}, Use(SliceOf(nodeFact, 2)).For("Children")).MustCreate().(*Node)
+			Ω(root.Children).Should(HaveLen(2))
+			Ω(root.Children[0].Name).Should(Equal("leaf"))
+			Ω(depths).Should(Equal([]int{2, 2}))
+		})
+
+		It("should return error if field type does not match", func() {
+			_, err := NewFactory(Street{}, Use(SliceOf(addrFact, 1)).For("Names")).Create()
+			Ω(err).Should(MatchError(`field "Names": []string can not hold factory_test.Address instances`))
+		})
+
+		It("should return error if field is not a slice", func() {
+			_, err := NewFactory(Address{}, Use(SliceOf(addrFact, 1)).For("City")).Create()
+			Ω(err).Should(MatchError(`field "City": not a slice`))
+		})
+
+		It("should panic on invalid count", func() {
+			Ω(func() { SliceOf(addrFact, "1") }).Should(PanicWithError(errors.New("expect count to be int or func() int but was: string")))
+		})
+	})
+
+	Describe("CountedSlice", func() {
+		type Post struct {
+			Tags   []string
+			Scores []int64
+			Addrs  []*Address
+			Title  string
+		}
+
+		It("should make slice of n values", func() {
+			p := NewFactory(
+				Post{},
+				Use(CountedSlice(3, SeqSelect("go", "test"))).For("Tags"),
+				Use(CountedSlice(func() int { return 2 }, SeqSelect(1, 2))).For("Scores"),
+			).MustCreate().(*Post)
+			Ω(p.Tags).Should(Equal([]string{"go", "test", "go"}))
+			Ω(p.Scores).Should(Equal([]int64{1, 2}))
+		})
+
+		It("should make slice of pointers", func() {
+			p := NewFactory(
+				Post{},
+				Use(CountedSlice(2, SeqSelect(Address{City: "CDMX"}, Address{City: "Cancun"}))).For("Addrs"),
+			).MustCreate().(*Post)
+			Ω(p.Addrs).Should(HaveLen(2))
+			Ω(p.Addrs[0].City).Should(Equal("CDMX"))
+			Ω(p.Addrs[1].City).Should(Equal("Cancun"))
+		})
+
+		It("should return error if field is not a slice", func() {
+			_, err := NewFactory(Post{}, Use(CountedSlice(1, SeqSelect("go"))).For("Title")).Create()
+			Ω(err).Should(MatchError(`field "Title": not a slice`))
+		})
+
+		It("should return error if values can't be assigned", func() {
+			_, err := NewFactory(Post{}, Use(CountedSlice(1, SeqSelect(1))).For("Tags")).Create()
+			Ω(err).Should(MatchError(`field "Tags": cannot assign int to string`))
+		})
+	})
+
+	Describe("MapOf", func() {
+		type Doc struct {
+			Tags   map[string]string
+			Scores map[string]int
+			Name   string
+		}
+
+		It("should make map of n entries", func() {
+			d := NewFactory(
+				Doc{},
+				Use(MapOf(3, SeqSelect("a", "b", "c"), SeqSelect("x", "y", "z"))).For("Tags"),
+				Use(MapOf(2, SeqSelect("a", "b"), SeqSelect(1, 2))).For("Scores"),
+			).MustCreate().(*Doc)
+
+			Ω(d.Tags).Should(Equal(map[string]string{"a": "x", "b": "y", "c": "z"}))
+			Ω(d.Scores).Should(Equal(map[string]int{"a": 1, "b": 2}))
+		})
+
+		It("should draw duplicate keys again", func() {
+			d := NewFactory(
+				Doc{},
+				Use(MapOf(3, SeqSelect("a", "a", "b", "b", "c"), SeqSelect("x"))).For("Tags"),
+			).MustCreate().(*Doc)
+			Ω(d.Tags).Should(Equal(map[string]string{"a": "x", "b": "x", "c": "x"}))
+		})
+
+		It("should stop drawing if keys are exhausted", func() {
+			d := NewFactory(
+				Doc{},
+				Use(MapOf(3, SeqSelect("a"), SeqSelect("x"))).For("Tags"),
+			).MustCreate().(*Doc)
+			Ω(d.Tags).Should(Equal(map[string]string{"a": "x"}))
+		})
+
+		It("should return error if field is not a map", func() {
+			_, err := NewFactory(Doc{}, Use(MapOf(1, SeqSelect("a"), SeqSelect("x"))).For("Name")).Create()
+			Ω(err).Should(MatchError(`field "Name": not a map`))
+		})
+
+		It("should return error if value type does not match", func() {
+			_, err := NewFactory(Doc{}, Use(MapOf(1, SeqSelect("a"), SeqSelect("x"))).For("Scores")).Create()
+			Ω(err).Should(MatchError(`field "Scores": cannot assign string to int`))
+		})
 	})
 })
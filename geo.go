@@ -0,0 +1,110 @@
+package factory
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// names of latitude and longitude fields GeoPoint fills in, compared case-insensitively
+var (
+	latNames = []string{"lat", "latitude"}
+	lngNames = []string{"lng", "lon", "long", "longitude"}
+)
+
+// Latitude randomly generates latitudes in degrees in interval [-90, 90)
+func Latitude() GeneratorFunc {
+	return FloatRange(-90, 90)
+}
+
+// Longitude randomly generates longitudes in degrees in interval [-180, 180)
+func Longitude() GeneratorFunc {
+	return FloatRange(-180, 180)
+}
+
+// GeoPoint randomly generates geographic coordinates for a struct field, or pointer to it, with float fields
+// named Lat or Latitude and Lng, Lon, Long or Longitude like struct{Lat, Lng float64}. Used for float fields
+// named so, like Use(GeoPoint()).For("Lat", "Lng"), it generates latitude or longitude depending on the name.
+func GeoPoint() GeneratorFunc {
+	lat, lng := Latitude(), Longitude()
+
+	// coord generates the coordinate the field is named after
+	coord := func(ctx Ctx, name string) (interface{}, bool, error) {
+		switch {
+		case matchName(name, latNames):
+			v, err := lat(ctx)
+			return v, true, err
+		case matchName(name, lngNames):
+			v, err := lng(ctx)
+			return v, true, err
+		}
+		return nil, false, nil
+	}
+
+	return func(ctx Ctx) (interface{}, error) {
+		if ctx.Instance == nil {
+			return nil, fmt.Errorf("expect instance to find out the type of field %q", ctx.Field)
+		}
+		typ, err := fieldType(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if isFloat(typ) {
+			v, ok, err := coord(ctx, ctx.Field[strings.LastIndex(ctx.Field, ".")+1:])
+			if !ok {
+				return nil, fieldError(ctx.Field, fmt.Errorf("expect latitude or longitude field but was: %s", ctx.Field))
+			}
+			return v, err
+		}
+
+		structType := typ
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+		if structType.Kind() != reflect.Struct {
+			return nil, fieldError(ctx.Field, fmt.Errorf("expect geo point struct field but was: %s", typ))
+		}
+
+		point := reflect.New(structType)
+		hasLat, hasLng := false, false
+		for i := 0; i < structType.NumField(); i++ {
+			sField := structType.Field(i)
+			if sField.PkgPath != "" || !isFloat(sField.Type) {
+				continue
+			}
+			v, ok, err := coord(ctx, sField.Name)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				point.Elem().Field(i).SetFloat(v.(float64))
+				isLat := matchName(sField.Name, latNames)
+				hasLat, hasLng = hasLat || isLat, hasLng || !isLat
+			}
+		}
+		if !hasLat || !hasLng {
+			return nil, fieldError(ctx.Field, fmt.Errorf("expect geo point struct field but was: %s", typ))
+		}
+
+		if typ.Kind() == reflect.Ptr {
+			return point.Interface(), nil
+		}
+		return point.Elem().Interface(), nil
+	}
+}
+
+// isFloat checks if typ is a float type
+func isFloat(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64
+}
+
+// matchName checks if the name is one of names ignoring case
+func matchName(name string, names []string) bool {
+	for _, n := range names {
+		if strings.EqualFold(name, n) {
+			return true
+		}
+	}
+	return false
+}
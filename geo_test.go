@@ -0,0 +1,73 @@
+package factory_test
+
+import (
+	"math/rand"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kolach/go-factory"
+)
+
+type Point struct {
+	Lat, Lng float64
+}
+
+type Place struct {
+	Name      string
+	Location  Point
+	Pin       *Point
+	Latitude  float32
+	Longitude float64
+	Altitude  float64
+	Area      Address
+}
+
+var _ = Describe("geo generators", func() {
+	It("should generate valid latitudes and longitudes", func() {
+		lat, lng := Latitude(), Longitude()
+		for i := 0; i < 100; i++ {
+			Ω(lat(Ctx{})).Should(And(BeNumerically(">=", -90), BeNumerically("<", 90)))
+			Ω(lng(Ctx{})).Should(And(BeNumerically(">=", -180), BeNumerically("<", 180)))
+		}
+	})
+
+	It("should fill geo point struct fields", func() {
+		f := NewFactory(Place{}, Use(GeoPoint()).For("Location", "Pin"))
+		for i := 0; i < 100; i++ {
+			p := f.MustCreate().(*Place)
+			Ω(p.Location.Lat).Should(And(BeNumerically(">=", -90), BeNumerically("<", 90)))
+			Ω(p.Location.Lng).Should(And(BeNumerically(">=", -180), BeNumerically("<", 180)))
+			Ω(p.Pin).ShouldNot(BeNil())
+			Ω(p.Pin.Lat).Should(And(BeNumerically(">=", -90), BeNumerically("<", 90)))
+		}
+	})
+
+	It("should fill separate float fields by their names", func() {
+		f := NewFactory(Place{}, Use(GeoPoint()).For("Latitude", "Longitude", "Location.Lat"))
+		for i := 0; i < 100; i++ {
+			p := f.MustCreate().(*Place)
+			Ω(p.Latitude).Should(And(BeNumerically(">=", -90), BeNumerically("<", 90)))
+			Ω(p.Longitude).Should(And(BeNumerically(">=", -180), BeNumerically("<", 180)))
+			Ω(p.Location.Lat).Should(And(BeNumerically(">=", -90), BeNumerically("<", 90)))
+		}
+	})
+
+	It("should draw from the factory random source", func() {
+		newFact := func() *Factory {
+			return NewFactory(Place{}, Use(GeoPoint()).For("Location")).WithRand(rand.New(rand.NewSource(42)))
+		}
+		Ω(newFact().MustCreateN(3)).Should(Equal(newFact().MustCreateN(3)))
+	})
+
+	It("should fail on fields other than geo points", func() {
+		_, err := NewFactory(Place{}, Use(GeoPoint()).For("Area")).Create()
+		Ω(err).Should(MatchError(`field "Area": expect geo point struct field but was: factory_test.Address`))
+
+		_, err = NewFactory(Place{}, Use(GeoPoint()).For("Name")).Create()
+		Ω(err).Should(MatchError(`field "Name": expect geo point struct field but was: string`))
+
+		_, err = NewFactory(Place{}, Use(GeoPoint()).For("Altitude")).Create()
+		Ω(err).Should(MatchError(`field "Altitude": expect latitude or longitude field but was: Altitude`))
+	})
+})
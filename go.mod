@@ -1,6 +1,6 @@
 module github.com/kolach/go-factory
 
-go 1.12
+go 1.18
 
 require (
 	github.com/Pallinder/go-randomdata v1.2.0
@@ -9,7 +9,16 @@ require (
 	github.com/onsi/ginkgo v1.10.2
 	github.com/onsi/gomega v1.7.0
 	github.com/satori/go.uuid v1.2.0
+)
+
+require (
+	github.com/hpcloud/tail v1.0.0 // indirect
+	github.com/pkg/errors v0.8.1 // indirect
 	golang.org/x/net v0.0.0-20190620200207-3b0461eec859 // indirect
 	golang.org/x/sync v0.0.0-20190423024810-112230192c58 // indirect
+	golang.org/x/sys v0.0.0-20190215142949-d0b11bdaac8a // indirect
+	golang.org/x/text v0.3.0 // indirect
+	gopkg.in/fsnotify.v1 v1.4.7 // indirect
+	gopkg.in/tomb.v1 v1.0.0-20141024135613-dd632973f1e7 // indirect
 	gopkg.in/yaml.v2 v2.2.2 // indirect
 )
@@ -0,0 +1,112 @@
+package factory
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// UUIDv4 generates random UUIDs. The value is assigned as [16]byte array to the fields
+// of types like uuid.UUID, as []byte to byte slices and as string like
+// "f47ac10b-58cc-4372-a567-0e02b2c3d479" to the rest of fields.
+func UUIDv4() GeneratorFunc {
+	return func(ctx Ctx) (interface{}, error) {
+		u, err := randomBytes(16)
+		if err != nil {
+			return nil, err
+		}
+		u[6] = (u[6] & 0x0f) | 0x40 // version 4
+		u[8] = (u[8] & 0x3f) | 0x80 // variant RFC 4122
+		s := fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+		return idValue(ctx, u, s)
+	}
+}
+
+// crockford is the alphabet of Crockford's base32 encoding used by ULID
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID generates lexicographically sortable identifiers of the current time in milliseconds
+// followed by random bits. The value is assigned as [16]byte array or []byte to byte fields
+// and as 26 characters string like "01ARZ3NDEKTSV4RRFFQ69G5FAV" to the rest of fields.
+func ULID() GeneratorFunc {
+	return func(ctx Ctx) (interface{}, error) {
+		u, err := randomBytes(16)
+		if err != nil {
+			return nil, err
+		}
+		ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+		for i := 5; i >= 0; i-- {
+			u[i] = byte(ms)
+			ms >>= 8
+		}
+
+		// encode 128 bits by 5 bits starting from the 2 leading bits
+		s := make([]byte, 26)
+		hi, lo := binary.BigEndian.Uint64(u[:8]), binary.BigEndian.Uint64(u[8:])
+		for i := 25; i >= 0; i-- {
+			s[i] = crockford[lo&0x1f]
+			lo = lo>>5 | hi<<59
+			hi >>= 5
+		}
+		return idValue(ctx, u, string(s))
+	}
+}
+
+// objectIDCounter is the counter part of ObjectID, it starts from random value
+var objectIDCounter = func() uint32 {
+	b, err := randomBytes(4)
+	if err != nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}()
+
+// ObjectID generates MongoDB-like object identifiers of the current time in seconds,
+// random bytes and counter. The value is assigned as [12]byte array like primitive.ObjectID
+// or []byte to byte fields and as 24 characters hex string to the rest of fields.
+func ObjectID() GeneratorFunc {
+	return func(ctx Ctx) (interface{}, error) {
+		id, err := randomBytes(12)
+		if err != nil {
+			return nil, err
+		}
+		binary.BigEndian.PutUint32(id[0:4], uint32(time.Now().Unix()))
+		n := atomic.AddUint32(&objectIDCounter, 1)
+		id[9], id[10], id[11] = byte(n>>16), byte(n>>8), byte(n)
+		return idValue(ctx, id, hex.EncodeToString(id))
+	}
+}
+
+// randomBytes reads n bytes from crypto random source
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// idValue returns identifier as byte array or slice if the field is of such type and as string otherwise
+func idValue(ctx Ctx, b []byte, s string) (interface{}, error) {
+	if ctx.Instance == nil {
+		return s, nil
+	}
+	typ, err := fieldType(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if typ.Kind() == reflect.Array && typ.Elem().Kind() == reflect.Uint8 && typ.Len() == len(b) {
+		val := reflect.New(typ).Elem()
+		reflect.Copy(val, reflect.ValueOf(b))
+		return val.Interface(), nil
+	}
+	if typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 {
+		return b, nil
+	}
+	return s, nil
+}
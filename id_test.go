@@ -0,0 +1,56 @@
+package factory_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	uuid "github.com/satori/go.uuid"
+
+	. "github.com/kolach/go-factory"
+)
+
+type Record struct {
+	ID    string
+	Key   [16]byte
+	Raw   []byte
+	OID   [12]byte
+	Label string
+}
+
+var _ = Describe("identity generators", func() {
+	Describe("UUIDv4", func() {
+		It("should generate UUID for string and array fields", func() {
+			u := NewFactory(User{}, Use(UUIDv4()).For("ID", "Username")).MustCreate().(*User)
+			Ω(u.ID.Version()).Should(Equal(uuid.V4))
+			Ω(u.ID.Variant()).Should(Equal(uuid.VariantRFC4122))
+			Ω(u.Username).Should(MatchRegexp(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`))
+		})
+
+		It("should generate string without instance", func() {
+			id, err := UUIDv4()(Ctx{})
+			Ω(err).Should(BeNil())
+			Ω(uuid.FromString(id.(string))).ShouldNot(BeNil())
+		})
+	})
+
+	Describe("ULID", func() {
+		It("should generate sortable ULID for string and byte fields", func() {
+			f := NewFactory(Record{}, Use(ULID()).For("ID", "Key", "Raw"))
+			r1, r2 := f.MustCreate().(*Record), f.MustCreate().(*Record)
+			Ω(r1.ID).Should(MatchRegexp(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`))
+			Ω(r1.Key).ShouldNot(Equal([16]byte{}))
+			Ω(r1.Raw).Should(HaveLen(16))
+			Ω(r1.ID).ShouldNot(Equal(r2.ID))
+			Ω(r1.ID[:10] <= r2.ID[:10]).Should(BeTrue())
+		})
+	})
+
+	Describe("ObjectID", func() {
+		It("should generate object ID for string and array fields", func() {
+			f := NewFactory(Record{}, Use(ObjectID()).For("Label", "OID"))
+			r1, r2 := f.MustCreate().(*Record), f.MustCreate().(*Record)
+			Ω(r1.Label).Should(MatchRegexp(`^[0-9a-f]{24}$`))
+			Ω(r1.OID).ShouldNot(Equal([12]byte{}))
+			Ω(r1.Label).ShouldNot(Equal(r2.Label))
+		})
+	})
+})
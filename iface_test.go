@@ -0,0 +1,79 @@
+package factory_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kolach/go-factory"
+)
+
+type PaymentMethod interface {
+	Pay(amount int) string
+}
+
+type CreditCard struct {
+	Number string
+}
+
+// Pay implements PaymentMethod with pointer receiver
+func (c *CreditCard) Pay(amount int) string {
+	return "card"
+}
+
+type Cash struct{}
+
+// Pay implements PaymentMethod with value receiver
+func (Cash) Pay(amount int) string {
+	return "cash"
+}
+
+type Order struct {
+	Payment PaymentMethod
+}
+
+type OrderCreated struct {
+	ID string
+}
+
+type Message struct {
+	Payload interface{}
+}
+
+var _ = Describe("interface fields", func() {
+	It("should assign implementations", func() {
+		f := NewFactory(Order{}, Use(RndSelect(&CreditCard{Number: "4242"}, Cash{})).For("Payment"))
+		for i := 0; i < 10; i++ {
+			o := f.MustCreate().(*Order)
+			Ω(o.Payment.Pay(1)).Should(BeElementOf("card", "cash"))
+			if o.Payment.Pay(1) == "card" {
+				Ω(o.Payment).Should(Equal(&CreditCard{Number: "4242"}))
+			}
+		}
+	})
+
+	It("should assign pointer created by sub-factory as is", func() {
+		orderFact := NewFactory(OrderCreated{}, Use("42").For("ID"))
+		e := NewFactory(Message{}, Use(orderFact).For("Payload")).MustCreate().(*Message)
+		Ω(e.Payload).Should(Equal(&OrderCreated{ID: "42"}))
+
+		cashFact := NewFactory(Cash{})
+		o := NewFactory(Order{}, Use(cashFact).For("Payment")).MustCreate().(*Order)
+		Ω(o.Payment).Should(Equal(&Cash{}))
+	})
+
+	It("should assign value as is", func() {
+		e := NewFactory(Message{}, Use(OrderCreated{ID: "42"}).For("Payload")).MustCreate().(*Message)
+		Ω(e.Payload).Should(Equal(OrderCreated{ID: "42"}))
+	})
+
+	It("should set nil", func() {
+		o := Order{Payment: Cash{}}
+		Ω(NewFactory(Order{}, Use(nil).For("Payment")).SetFields(&o)).Should(Succeed())
+		Ω(o.Payment).Should(BeNil())
+	})
+
+	It("should return error if value does not implement interface", func() {
+		_, err := NewFactory(Order{}, Use(CreditCard{}).For("Payment")).Create()
+		Ω(err).Should(MatchError(`field "Payment": factory_test.CreditCard does not implement factory_test.PaymentMethod`))
+	})
+})
@@ -0,0 +1,43 @@
+package factory
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// CreateJSON makes a new instance and marshals it to JSON honoring json tags of the struct
+func (f *Factory) CreateJSON(fieldGenFuncs ...FieldGenFunc) ([]byte, error) {
+	i, err := f.Create(fieldGenFuncs...)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(i)
+}
+
+// CreateNJSON makes n new instances and marshals them to JSON array
+func (f *Factory) CreateNJSON(n int, fieldGenFuncs ...FieldGenFunc) ([]byte, error) {
+	instances, err := f.CreateN(n, fieldGenFuncs...)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(instances)
+}
+
+// ProtoFromJSON unmarshals JSON document into a new value of the type of typ, that is
+// a struct value or a pointer to it, and returns the struct value to be used as a proto object.
+// Unknown fields in the document make it return an error to catch typos.
+func ProtoFromJSON(typ interface{}, data []byte) (interface{}, error) {
+	t := reflect.TypeOf(typ)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	proto := reflect.New(t)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(proto.Interface()); err != nil {
+		return nil, err
+	}
+	return proto.Elem().Interface(), nil
+}
@@ -0,0 +1,63 @@
+package factory_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kolach/go-factory"
+)
+
+var _ = Describe("CreateJSON", func() {
+	var addrFact *Factory
+
+	BeforeEach(func() {
+		addrFact = NewFactory(Address{}, Use(SeqSelect("CDMX", "Cancun")).For("City"))
+	})
+
+	It("should marshal instance honoring json tags", func() {
+		data, err := NewFactory(Node{}, Use("root").For("Name")).CreateJSON()
+		Ω(err).Should(BeNil())
+		Ω(data).Should(MatchJSON(`{"name": "root", "children": null}`))
+	})
+
+	It("should marshal n instances to array", func() {
+		data, err := addrFact.CreateNJSON(2, Use("Mexicali").For("Street"))
+		Ω(err).Should(BeNil())
+		Ω(data).Should(MatchJSON(`[{"City": "CDMX", "Street": "Mexicali"}, {"City": "Cancun", "Street": "Mexicali"}]`))
+	})
+
+	It("should return generator errors", func() {
+		_, err := addrFact.CreateJSON(Use(func() (string, error) { return "", errors.New("boom") }).For("City"))
+		Ω(err).Should(MatchError(`field "City": boom`))
+	})
+})
+
+var _ = Describe("ProtoFromJSON", func() {
+	It("should make proto object from JSON", func() {
+		proto, err := ProtoFromJSON(Address{}, []byte(`{"City": "CDMX"}`))
+		Ω(err).Should(BeNil())
+		Ω(proto).Should(Equal(Address{City: "CDMX"}))
+
+		a := NewFactory(proto, Use("Mexicali").For("Street")).MustCreate().(*Address)
+		Ω(a.City).Should(Equal("CDMX"))
+		Ω(a.Street).Should(Equal("Mexicali"))
+	})
+
+	It("should accept pointer to type", func() {
+		proto, err := ProtoFromJSON(&Node{}, []byte(`{"name": "root"}`))
+		Ω(err).Should(BeNil())
+		Ω(proto).Should(Equal(Node{Name: "root"}))
+	})
+
+	It("should return error on unknown fields", func() {
+		_, err := ProtoFromJSON(Address{}, []byte(`{"Cty": "CDMX"}`))
+		Ω(err).Should(MatchError(`json: unknown field "Cty"`))
+	})
+
+	It("should return error on malformed JSON", func() {
+		_, err := ProtoFromJSON(Address{}, []byte(`{"City": `))
+		Ω(err).ShouldNot(BeNil())
+	})
+})
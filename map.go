@@ -0,0 +1,105 @@
+package factory
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// CreateMap makes a new instance and returns its exported fields as a map.
+// The keys are the names from json tags, if present, or field names.
+// The factory of maps returns the created map itself.
+func (f *Factory) CreateMap(fieldGenFuncs ...FieldGenFunc) (map[string]interface{}, error) {
+	i, err := f.Create(fieldGenFuncs...)
+	if err != nil {
+		return nil, err
+	}
+	return toMap(reflect.ValueOf(i).Elem()), nil
+}
+
+// CreateNMap makes n new instances and returns their exported fields as maps
+func (f *Factory) CreateNMap(n int, fieldGenFuncs ...FieldGenFunc) ([]map[string]interface{}, error) {
+	instances, err := f.CreateN(n, fieldGenFuncs...)
+	if err != nil {
+		return nil, err
+	}
+
+	maps := make([]map[string]interface{}, len(instances))
+	for i, instance := range instances {
+		maps[i] = toMap(reflect.ValueOf(instance).Elem())
+	}
+	return maps, nil
+}
+
+// toMap converts struct value into a map keyed by json names of exported fields,
+// map value is converted to map with string keys
+func toMap(val reflect.Value) map[string]interface{} {
+	if val.Kind() == reflect.Map {
+		if m, ok := val.Interface().(map[string]interface{}); ok {
+			return m
+		}
+		// walk the keys sorted so the entries are consumed in the same order on every run
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		m := make(map[string]interface{}, len(keys))
+		for _, key := range keys {
+			m[key.String()] = val.MapIndex(key).Interface()
+		}
+		return m
+	}
+
+	typ := val.Type()
+	m := make(map[string]interface{}, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		sField := typ.Field(i)
+		if sField.PkgPath != "" {
+			continue
+		}
+		name, omitEmpty, skip := jsonName(sField)
+		if skip || omitEmpty && isEmptyValue(val.Field(i)) {
+			continue
+		}
+		m[name] = val.Field(i).Interface()
+	}
+	return m
+}
+
+// jsonName returns the field name from json tag or the field name if there is no tag,
+// whether the field is omitted if empty and whether the field is skipped with "-" tag
+// like encoding/json does.
+func jsonName(sField reflect.StructField) (name string, omitEmpty, skip bool) {
+	tag := sField.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	opts := strings.Split(tag, ",")
+	name = opts[0]
+	if name == "" {
+		name = sField.Name
+	}
+	for _, opt := range opts[1:] {
+		omitEmpty = omitEmpty || opt == "omitempty"
+	}
+	return name, omitEmpty, false
+}
+
+// isEmptyValue checks if the value is empty in terms of json omitempty option
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,161 @@
+package factory_test
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kolach/go-factory"
+	. "github.com/kolach/gomega-matchers"
+)
+
+var _ = Describe("CreateMap", func() {
+	var nodeFact *Factory
+
+	BeforeEach(func() {
+		nodeFact = NewFactory(Node{}, Use("root").For("Name"))
+	})
+
+	It("should return fields as map keyed by json names", func() {
+		m, err := nodeFact.CreateMap()
+		Ω(err).Should(BeNil())
+		Ω(m).Should(HaveKeyWithValue("name", "root"))
+		Ω(m).Should(HaveKey("children"))
+	})
+
+	It("should use field names if there are no json tags", func() {
+		m, err := NewFactory(Address{}, Use("CDMX").For("City")).CreateMap()
+		Ω(err).Should(BeNil())
+		Ω(m).Should(Equal(map[string]interface{}{"City": "CDMX", "Street": ""}))
+	})
+
+	It("should make n maps", func() {
+		maps, err := nodeFact.CreateNMap(2, Use(SeqSelect("a", "b")).For("Name"))
+		Ω(err).Should(BeNil())
+		Ω(maps).Should(HaveLen(2))
+		Ω(maps[0]["name"]).Should(Equal("a"))
+		Ω(maps[1]["name"]).Should(Equal("b"))
+	})
+
+	It("should return generator errors", func() {
+		_, err := nodeFact.CreateMap(Use(func() (string, error) { return "", errors.New("boom") }).For("Name"))
+		Ω(err).Should(MatchError(`field "Name": boom`))
+	})
+
+	It("should serialize maps the same way on every run", func() {
+		for i := 0; i < 10; i++ {
+			maps, err := NewFactory(Address{}, Use("CDMX").For("City")).CreateNMap(2)
+			Ω(err).Should(BeNil())
+			data, err := json.Marshal(maps)
+			Ω(err).Should(BeNil())
+			Ω(string(data)).Should(Equal(`[{"City":"CDMX","Street":""},{"City":"CDMX","Street":""}]`))
+		}
+	})
+
+	It("should skip fields tagged with json dash", func() {
+		m, err := nodeFact.CreateMap()
+		Ω(err).Should(BeNil())
+		Ω(m).ShouldNot(HaveKey("Parent"))
+		Ω(m).ShouldNot(HaveKey("-"))
+	})
+
+	It("should omit empty fields like encoding/json", func() {
+		type Doc struct {
+			Title   string            `json:"title,omitempty"`
+			Draft   bool              `json:",omitempty"`
+			Views   int               `json:"views,omitempty"`
+			Tags    []string          `json:"tags,omitempty"`
+			Meta    map[string]string `json:"meta,omitempty"`
+			Author  *User             `json:"author,omitempty"`
+			Dash    string            `json:"-,"`
+			Summary string            `json:"summary"`
+		}
+
+		m, err := NewFactory(Doc{}).CreateMap()
+		Ω(err).Should(BeNil())
+		Ω(m).Should(Equal(map[string]interface{}{"-": "", "summary": ""}))
+
+		f := NewFactory(Doc{}, Use("go").For("Title"), Use(true).For("Draft"), Use(3).For("Views"), Use([]string{"a"}).For("Tags"))
+		m, err = f.CreateMap()
+		Ω(err).Should(BeNil())
+		Ω(m).Should(HaveKeyWithValue("title", "go"))
+		Ω(m).Should(HaveKeyWithValue("Draft", true))
+		Ω(m).Should(HaveKeyWithValue("views", 3))
+		Ω(m).Should(HaveKeyWithValue("tags", []string{"a"}))
+
+		data, err := f.CreateJSON()
+		Ω(err).Should(BeNil())
+		var doc map[string]interface{}
+		Ω(json.Unmarshal(data, &doc)).Should(Succeed())
+		Ω(doc).Should(HaveLen(len(m)))
+		for key := range m {
+			Ω(doc).Should(HaveKey(key))
+		}
+	})
+})
+
+var _ = Describe("Map factory", func() {
+	var docFact *Factory
+
+	BeforeEach(func() {
+		docFact = NewFactory(
+			map[string]interface{}{"kind": "doc", "tags": []string{"a"}},
+			Use(SeqSelect("john", "jane")).For("author"),
+			Use(CopyField("author")).For("owner").DependsOn("author"),
+			Use(NewFactory(Address{}, Use("CDMX").For("City"))).For("address.home"),
+		)
+	})
+
+	It("should generate map entries", func() {
+		doc := *docFact.MustCreate().(*map[string]interface{})
+		Ω(doc).Should(HaveKeyWithValue("kind", "doc"))
+		Ω(doc).Should(HaveKeyWithValue("tags", []string{"a"}))
+		Ω(doc).Should(HaveKeyWithValue("author", "john"))
+		Ω(doc).Should(HaveKeyWithValue("owner", "john"))
+		Ω(doc).Should(HaveKeyWithValue("address.home", &Address{City: "CDMX"}))
+	})
+
+	It("should not share reference values of proto", func() {
+		doc := *docFact.MustCreate().(*map[string]interface{})
+		doc["tags"].([]string)[0] = "b"
+		Ω(*docFact.MustCreate().(*map[string]interface{})).Should(HaveKeyWithValue("tags", []string{"a"}))
+	})
+
+	It("should set entries of existing map", func() {
+		doc := map[string]interface{}{"id": 1}
+		Ω(docFact.SetFields(&doc)).Should(Succeed())
+		Ω(doc).Should(HaveKeyWithValue("id", 1))
+		Ω(doc).Should(HaveKeyWithValue("kind", "doc"))
+	})
+
+	It("should return map from CreateMap", func() {
+		doc, err := docFact.CreateMap(Use(nil).For("kind"))
+		Ω(err).Should(BeNil())
+		Ω(doc).Should(HaveKeyWithValue("kind", BeNil()))
+	})
+
+	It("should generate proto entries in order of keys", func() {
+		f := NewFactory(map[string]int{"c": 1, "a": 2, "d": 3, "b": 4}, Use(5).For("e"))
+		for i := 0; i < 10; i++ {
+			Ω(f.Fields()).Should(Equal([]string{"a", "b", "c", "d", "e"}))
+		}
+	})
+
+	It("should serialize maps the same way on every run", func() {
+		type Key string
+		f := NewFactory(map[Key]interface{}{"c": 1, "a": "x", "b": true}, Use(SeqSelect("john")).For("author"))
+		for i := 0; i < 10; i++ {
+			doc, err := f.CreateMap()
+			Ω(err).Should(BeNil())
+			data, err := json.Marshal(doc)
+			Ω(err).Should(BeNil())
+			Ω(string(data)).Should(Equal(`{"a":"x","author":"john","b":true,"c":1}`))
+		}
+	})
+
+	It("should panic on map with not string keys", func() {
+		Ω(func() { NewFactory(map[int]string{}, Use("x").For("1")) }).Should(PanicWithError(errors.New(`can not set key "1" of map[int]string, expect string keys`)))
+	})
+})
@@ -36,3 +36,53 @@ func BenchmarkProtoEmpty(b *testing.B) {
 		f.MustCreate()
 	}
 }
+
+// Factory with zero proto object preserving the fields already set,
+// compare with BenchmarkProtoEmpty to see the cost of checking the fields
+func BenchmarkProtoEmptyPreserve(b *testing.B) {
+	f := NewFactory(
+		User{},
+		Use("John").For("FirstName"),
+		Use("Smith").For("LastName"),
+		Use("john").For("Username"),
+		Use("john@hotmail.com").For("Email"),
+		Use(30).For("Age"),
+		Use(false).For("Married"),
+	).Derive(Preserve())
+	for i := 0; i < b.N; i++ {
+		f.MustSetFields(&User{Username: "jane"})
+	}
+}
+
+// Factory with the same values returned by generator functions,
+// compare with BenchmarkProtoEmpty to see the gain of static values
+func BenchmarkGeneratorFuncs(b *testing.B) {
+	value := func(v interface{}) GeneratorFunc {
+		return func(Ctx) (interface{}, error) { return v, nil }
+	}
+	f := NewFactory(
+		User{},
+		Use(value("John")).For("FirstName"),
+		Use(value("Smith")).For("LastName"),
+		Use(value("john")).For("Username"),
+		Use(value("john@hotmail.com")).For("Email"),
+		Use(value(30)).For("Age"),
+		Use(value(false)).For("Married"),
+	)
+	for i := 0; i < b.N; i++ {
+		f.MustCreate()
+	}
+}
+
+// Instances created with field generators derive the factory on each call,
+// the fields are looked up by name once per type and path
+func BenchmarkDerive(b *testing.B) {
+	f := NewFactory(User{})
+	for i := 0; i < b.N; i++ {
+		f.MustCreate(
+			Use("John").For("FirstName"),
+			Use("Smith").For("LastName"),
+			Use(30).For("Age"),
+		)
+	}
+}
@@ -0,0 +1,48 @@
+package factory
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// globalRand is the random source of factories that have no own one.
+// It's seeded once on start and safe for concurrent use.
+var globalRand = rand.New(newLockedSource(time.Now().UnixNano()))
+
+// lockedRand wraps r into a random source safe for concurrent use that yields the same values as r
+func lockedRand(r *rand.Rand) *rand.Rand {
+	return rand.New(&lockedSource{src: r})
+}
+
+// lockedSource is a random source safe for concurrent use
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source64
+}
+
+// newLockedSource makes a locked source seeded with seed
+func newLockedSource(seed int64) *lockedSource {
+	return &lockedSource{src: rand.NewSource(seed).(rand.Source64)}
+}
+
+// Int63 implements rand.Source
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+// Uint64 implements rand.Source64
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+// Seed implements rand.Source
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
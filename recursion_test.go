@@ -90,6 +90,113 @@ var _ = Describe("RecursionTest", func() {
 		Ω(callDepths).Should(Equal([]int{1, 2, 3, 4, 5}))
 	})
 
+	Describe("WithMaxDepth", func() {
+		type Chain struct {
+			Next  *Chain
+			Depth int
+		}
+
+		var chainFact *Factory
+
+		BeforeEach(func() {
+			chainFact = NewFactory(
+				Chain{},
+				Use(func(ctx Ctx) (interface{}, error) {
+					return ctx.Factory.CallDepth(), nil
+				}).For("Depth"),
+				Use(func(ctx Ctx) (interface{}, error) {
+					return ctx.Factory.Create()
+				}).For("Next"),
+			).WithMaxDepth(3)
+		})
+
+		It("should stop recursion at max depth", func() {
+			c := chainFact.MustCreate().(*Chain)
+			Ω(c.Depth).Should(Equal(1))
+			Ω(c.Next.Depth).Should(Equal(2))
+			Ω(c.Next.Next.Depth).Should(Equal(3))
+			Ω(c.Next.Next.Next).Should(BeNil())
+		})
+
+		It("should stop recursion passing through sub-factories", func() {
+			depth := Use(func(ctx Ctx) (interface{}, error) {
+				return ctx.Factory.CallDepth(), nil
+			}).For("Depth")
+
+			b := NewFactory(Chain{})
+			a := NewFactory(Chain{}, depth, Use(b).For("Next")).WithMaxDepth(3)
+			// tie the knot: b uses a that uses b
+			*b = *NewFactory(Chain{}, depth, Use(a).For("Next"))
+
+			depths := []int{}
+			for c := a.MustCreate().(*Chain); c != nil; c = c.Next {
+				depths = append(depths, c.Depth)
+			}
+			// b has no max depth of its own, so it makes one more level before a stops
+			Ω(depths).Should(Equal([]int{1, 2, 3, 4}))
+		})
+
+		It("should expose max depth to generators", func() {
+			var maxDepth int
+			chainFact.MustCreate(Use(func(ctx Ctx) (interface{}, error) {
+				maxDepth = ctx.Factory.MaxDepth()
+				return nil, nil
+			}).For("Next"))
+			Ω(maxDepth).Should(Equal(3))
+		})
+
+		It("should leave instance untouched in SetFields deeper than max depth", func() {
+			kids := []*Node{}
+			factory.WithMaxDepth(1).MustCreate(Use(func(ctx Ctx) (interface{}, error) {
+				kid := &Node{Name: "kid"}
+				kids = append(kids, kid)
+				return nil, ctx.Factory.SetFields(kid)
+			}).For("Children"))
+			Ω(kids).Should(HaveLen(1))
+			Ω(kids[0].Name).Should(Equal("kid"))
+		})
+	})
+
+	Describe("Parent", func() {
+		var nodeFact *Factory
+
+		BeforeEach(func() {
+			nodeFact = NewFactory(
+				Node{},
+				Use(func(ctx Ctx) (interface{}, error) {
+					parent, _ := ctx.Parent.(*Node)
+					return parent, nil
+				}).For("Parent"),
+			).WithMaxDepth(3)
+		})
+
+		It("should expose parent instance to recursive calls", func() {
+			root := nodeFact.MustCreate(Use(func(ctx Ctx) (interface{}, error) {
+				kid := &Node{}
+				return []*Node{kid}, ctx.Factory.SetFields(kid)
+			}).For("Children")).(*Node)
+			Ω(root.Parent).Should(BeNil())
+			Ω(root.Children[0].Parent).Should(BeIdenticalTo(root))
+			Ω(root.Children[0].Children[0].Parent).Should(BeIdenticalTo(root.Children[0]))
+		})
+
+		It("should expose parent instance to sub-factories", func() {
+			root := nodeFact.MustCreate(Use(SliceOf(nodeFact, 2)).For("Children")).(*Node)
+			Ω(root.Children).Should(HaveLen(2))
+			for _, kid := range root.Children {
+				Ω(kid.Parent).Should(BeIdenticalTo(root))
+			}
+
+			var parent interface{}
+			addrFact := NewFactory(Address{}).AfterCreate(func(ctx Ctx) error {
+				parent = ctx.Parent
+				return nil
+			})
+			c := NewFactory(Customer{}, Use(addrFact).For("Address")).MustCreate()
+			Ω(parent).Should(BeIdenticalTo(c))
+		})
+	})
+
 	It("should be OK to use factory concurrently", func() {
 		numCPU := runtime.NumCPU()
 		if numCPU == 1 {
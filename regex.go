@@ -0,0 +1,119 @@
+package factory
+
+import (
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// regexMaxRepeat is the max number of repetitions of unbounded repeats like `*`, `+` and `{n,}`
+const regexMaxRepeat = 10
+
+// printable is the range of printable ASCII characters the character classes are narrowed to
+var printable = []rune{' ', '~'}
+
+// Regex returns generator of random strings matching the regular expression pattern,
+// for example `\d{3}-\d{4}` for phone numbers. Unbounded repeats like `*` and `+` repeat
+// up to 10 times and character classes are narrowed to printable ASCII characters if they
+// have some. Anchors and word boundaries are ignored. It panics if the pattern is invalid.
+func Regex(pattern string) GeneratorFunc {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		panic(err)
+	}
+	re = re.Simplify()
+
+	return func(ctx Ctx) (interface{}, error) {
+		var sb strings.Builder
+		generateRegex(ctx, &sb, re)
+		return sb.String(), nil
+	}
+}
+
+// generateRegex writes random string matching the regular expression re to sb
+func generateRegex(ctx Ctx, sb *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && randIntn(ctx, 2) == 0 {
+				r = unicode.SimpleFold(r)
+			}
+			sb.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		if len(re.Rune) > 0 {
+			sb.WriteRune(randRune(ctx, re.Rune))
+		}
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune(randRune(ctx, printable))
+	case syntax.OpCapture:
+		generateRegex(ctx, sb, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			generateRegex(ctx, sb, sub)
+		}
+	case syntax.OpAlternate:
+		generateRegex(ctx, sb, re.Sub[randIntn(ctx, len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := repeatRange(re)
+		for n := min + randIntn(ctx, max-min+1); n > 0; n-- {
+			generateRegex(ctx, sb, re.Sub[0])
+		}
+	}
+}
+
+// repeatRange returns min and max number of repetitions of the repeat expression re
+func repeatRange(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, regexMaxRepeat
+	case syntax.OpPlus:
+		return 1, regexMaxRepeat
+	case syntax.OpQuest:
+		return 0, 1
+	}
+	if re.Max < 0 {
+		return re.Min, re.Min + regexMaxRepeat
+	}
+	return re.Min, re.Max
+}
+
+// randRune picks random rune of the ranges given as pairs of the first and last rune,
+// preferring printable ASCII characters if the ranges have some.
+func randRune(ctx Ctx, ranges []rune) rune {
+	if narrowed := intersectRanges(ranges, printable); len(narrowed) > 0 {
+		ranges = narrowed
+	}
+
+	total := 0
+	for i := 0; i < len(ranges); i += 2 {
+		total += int(ranges[i+1]-ranges[i]) + 1
+	}
+	n := randIntn(ctx, total)
+	for i := 0; i < len(ranges); i += 2 {
+		size := int(ranges[i+1]-ranges[i]) + 1
+		if n < size {
+			return ranges[i] + rune(n)
+		}
+		n -= size
+	}
+	return ranges[0]
+}
+
+// intersectRanges returns the parts of ranges within the range bounds
+func intersectRanges(ranges []rune, bounds []rune) []rune {
+	result := []rune{}
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < bounds[0] {
+			lo = bounds[0]
+		}
+		if hi > bounds[1] {
+			hi = bounds[1]
+		}
+		if lo <= hi {
+			result = append(result, lo, hi)
+		}
+	}
+	return result
+}
@@ -0,0 +1,43 @@
+package factory_test
+
+import (
+	"math/rand"
+	"regexp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kolach/go-factory"
+)
+
+var _ = Describe("Regex", func() {
+	It("should generate strings matching the pattern", func() {
+		for _, pattern := range []string{
+			`\d{3}-\d{4}`,
+			`[A-Z]{2}\d{5}(-\d{4})?`,
+			`(foo|bar)+_[a-z0-9]*`,
+			`(?i)abc\.[^a-z]\w{2,}`,
+			`^\+1 \(\d{3}\) \d{3}-\d{4}$`,
+		} {
+			gen := Regex(pattern)
+			re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+			for i := 0; i < 100; i++ {
+				s, err := gen(Ctx{})
+				Ω(err).Should(BeNil())
+				Ω(re.MatchString(s.(string))).Should(BeTrue(), "%q should match %s", s, pattern)
+			}
+		}
+	})
+
+	It("should generate the same strings from seeded random source", func() {
+		f := NewFactory(User{}, Use(Regex(`[a-z]{5,10}`)).For("Username"))
+		gen := func() []interface{} {
+			return f.WithRand(rand.New(rand.NewSource(42))).MustCreateN(3)
+		}
+		Ω(gen()).Should(Equal(gen()))
+	})
+
+	It("should panic on invalid pattern", func() {
+		Ω(func() { Regex(`[a-`) }).Should(Panic())
+	})
+})
@@ -0,0 +1,147 @@
+package factory
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// DefaultRegistryDepth is the call depth up to which the instances are created
+// by the factories found in Registry.
+const DefaultRegistryDepth = 3
+
+// Registry keeps factories by the type of instances they create, so the factory that uses it
+// fills in struct and pointer to struct fields without explicit generators. See Factory.WithRegistry.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[reflect.Type]*Factory
+	maxDepth  int
+}
+
+// NewRegistry makes a registry of factories
+func NewRegistry(factories ...*Factory) *Registry {
+	r := &Registry{factories: make(map[reflect.Type]*Factory), maxDepth: DefaultRegistryDepth}
+	for _, f := range factories {
+		r.Register(f)
+	}
+	return r
+}
+
+// Register adds factory f to the registry replacing the factory of the same type, if any
+func (r *Registry) Register(f *Factory) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[f.typ] = f
+	return r
+}
+
+// Lookup returns the factory of instances of type typ
+func (r *Registry) Lookup(typ reflect.Type) (*Factory, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	f, ok := r.factories[typ]
+	return f, ok
+}
+
+// SetMaxDepth sets the call depth up to which the instances are created by registered factories,
+// so the types referring to themselves make chains of n instances at most.
+func (r *Registry) SetMaxDepth(n int) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxDepth = n
+	return r
+}
+
+// depth returns max call depth of the registry
+func (r *Registry) depth() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.maxDepth
+}
+
+// WithRegistry produces a new factory that fills in the struct and pointer to struct fields
+// that have no generators, including nested ones, with the factories of the field types found in r.
+// The registry is passed down to the factories, so they fill in their fields alike.
+func (f *Factory) WithRegistry(r *Registry) *Factory {
+	d := *f
+	d.registry = r
+	d.bindRegistry()
+	return &d
+}
+
+// bindRegistry makes the generators of fields to fill in with the factories of the registry once the
+// registry or field generators are set, so instances are created without looking for such fields
+func (f *Factory) bindRegistry() {
+	f.regGens = nil
+	if regGens := f.registryGens(); len(regGens) > 0 {
+		f.regGens = append(regGens, f.fieldGens...)
+	}
+}
+
+// registryGens makes generators of the fields to fill in with the factories of the registry
+func (f *Factory) registryGens() []fieldWithGen {
+	if f.registry == nil || f.typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fieldGens := []fieldWithGen{}
+	for i := 0; i < f.typ.NumField(); i++ {
+		sField := f.typ.Field(i)
+		if sField.PkgPath != "" || f.hasFieldGen(sField.Name) {
+			continue
+		}
+
+		typ := sField.Type
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			continue
+		}
+
+		fieldGens = append(fieldGens, fieldWithGen{StructField: &sField, gen: registryGen(f.registry, typ)})
+	}
+	return fieldGens
+}
+
+// hasFieldGen checks if the factory has generator of the field or its nested fields
+func (f *Factory) hasFieldGen(name string) bool {
+	for _, fg := range f.fieldGens {
+		if fg.Name == name || strings.HasPrefix(fg.Name, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// registryGen makes generator that creates instance of type typ with the factory registered for it
+// unless max call depth of the registry is reached. The field is left as it is if there is no such factory.
+// The factory is looked up on every call, so the factories registered later are used too, and so are
+// hooks and traits added to registered factories later. Only the registry generators of registered
+// factories are prepared once, as their field generators never change.
+func registryGen(r *Registry, typ reflect.Type) GeneratorFunc {
+	// registry generators of registered factories, prepared on first use
+	// as they may refer to the type being prepared
+	var prepared sync.Map // *Factory -> []fieldWithGen
+
+	return func(ctx Ctx) (interface{}, error) {
+		if ctx.Factory.CallDepth() >= r.depth() {
+			return nil, nil
+		}
+		f, ok := r.Lookup(typ)
+		if !ok {
+			return Unset, nil
+		}
+
+		sub := subFactory(f, ctx)
+		if sub.registry == nil {
+			regGens, ok := prepared.Load(f)
+			if !ok {
+				withReg := f.WithRegistry(r)
+				regGens, _ = prepared.LoadOrStore(f, withReg.regGens)
+			}
+			sub.registry, sub.regGens = r, regGens.([]fieldWithGen)
+		}
+		return sub.Create()
+	}
+}
@@ -0,0 +1,107 @@
+package factory_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kolach/go-factory"
+)
+
+type Company struct {
+	Name    string
+	Owner   User
+	Address *Address
+	Parent  *Company
+}
+
+var _ = Describe("Registry", func() {
+	var (
+		registry    *Registry
+		companyFact *Factory
+	)
+
+	BeforeEach(func() {
+		addrFact := NewFactory(Address{}, Use("CDMX").For("City"))
+		userFact := NewFactory(User{}, Use("john").For("Username"))
+		companyFact = NewFactory(Company{}, Use("ACME").For("Name"))
+		registry = NewRegistry(addrFact, userFact, companyFact)
+	})
+
+	It("should look up factories by type", func() {
+		f, ok := registry.Lookup(reflect.TypeOf(Address{}))
+		Ω(ok).Should(BeTrue())
+		Ω(f.Type()).Should(Equal(reflect.TypeOf(Address{})))
+
+		_, ok = registry.Lookup(reflect.TypeOf(Customer{}))
+		Ω(ok).Should(BeFalse())
+	})
+
+	It("should fill in fields without generators with registered factories", func() {
+		c := companyFact.WithRegistry(registry).MustCreate().(*Company)
+		Ω(c.Owner.Username).Should(Equal("john"))
+		Ω(c.Owner.Address.City).Should(Equal("CDMX"))
+		Ω(c.Address.City).Should(Equal("CDMX"))
+	})
+
+	It("should keep explicit generators", func() {
+		c := companyFact.WithRegistry(registry).MustCreate(
+			Use("jane").For("Owner.Username"),
+			Use(nil).For("Address"),
+		).(*Company)
+		Ω(c.Owner.Username).Should(Equal("jane"))
+		Ω(c.Owner.Address.City).Should(BeEmpty())
+		Ω(c.Address).Should(BeNil())
+	})
+
+	It("should use factories registered later", func() {
+		r := NewRegistry()
+		f := companyFact.WithRegistry(r)
+		Ω(f.MustCreate().(*Company).Address).Should(BeNil())
+
+		r.Register(NewFactory(Address{}, Use("Merida").For("City")))
+		Ω(f.MustCreate().(*Company).Address.City).Should(Equal("Merida"))
+	})
+
+	It("should run hooks added to registered factories later", func() {
+		addrFact := NewFactory(Address{}, Use("CDMX").For("City"))
+		f := companyFact.WithRegistry(NewRegistry(addrFact))
+		Ω(f.MustCreate().(*Company).Address.Street).Should(BeEmpty())
+
+		addrFact.AfterCreate(func(ctx Ctx) error {
+			ctx.Instance.(*Address).Street = "Reforma"
+			return nil
+		})
+		c := f.MustCreate().(*Company)
+		Ω(c.Address.Street).Should(Equal("Reforma"))
+		Ω(c.Owner.Address.Street).Should(BeEmpty())
+	})
+
+	It("should fill in fields of derived factories", func() {
+		f := companyFact.WithRegistry(registry).Derive(Use("jane").For("Owner.Username"))
+		c := f.MustCreate().(*Company)
+		Ω(c.Owner.Username).Should(Equal("jane"))
+		Ω(c.Address.City).Should(Equal("CDMX"))
+
+		c = f.Derive(Omit("Owner")).MustCreate().(*Company)
+		Ω(c.Owner.Username).Should(Equal("john"))
+	})
+
+	It("should stop recursion at max depth", func() {
+		c := companyFact.WithRegistry(registry.SetMaxDepth(2)).MustCreate().(*Company)
+		Ω(c.Parent).ShouldNot(BeNil())
+		Ω(c.Parent.Name).Should(Equal("ACME"))
+		Ω(c.Parent.Parent).Should(BeNil())
+	})
+})
+
+// Factory filling in fields with registered factories, the fields are found once on WithRegistry
+func BenchmarkRegistry(b *testing.B) {
+	registry := NewRegistry(NewFactory(Address{}, Use("CDMX").For("City")))
+	f := NewFactory(Company{}, Use("ACME").For("Name")).WithRegistry(registry)
+	for i := 0; i < b.N; i++ {
+		f.MustCreate()
+	}
+}
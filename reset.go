@@ -0,0 +1,100 @@
+package factory
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Resettable is implemented by the state of stateful generators like Sequence,
+// SeqSelect and Unique, so that Factory.Reset can make them start over.
+type Resettable interface {
+	Reset()
+}
+
+// resetSet is a set of resettables tracked by factory. It's shared by the factory
+// and its derived copies as they share the generators.
+type resetSet struct {
+	mu        sync.Mutex
+	items     map[Resettable]struct{}
+	order     []Resettable // items in order of tracking, so they are reset in a stable order
+	resetting bool         // guards against reset loops of factories using each other
+}
+
+// add puts r into the set
+func (s *resetSet) add(r Resettable) {
+	if r == Resettable(s) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.items == nil {
+		s.items = make(map[Resettable]struct{})
+	}
+	if _, ok := s.items[r]; !ok {
+		s.items[r] = struct{}{}
+		s.order = append(s.order, r)
+	}
+}
+
+// Reset resets all the items of the set
+func (s *resetSet) Reset() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.resetting {
+		s.mu.Unlock()
+		return
+	}
+	s.resetting = true
+	items := append([]Resettable(nil), s.order...)
+	s.mu.Unlock()
+
+	for _, r := range items {
+		r.Reset()
+	}
+
+	s.mu.Lock()
+	s.resetting = false
+	s.mu.Unlock()
+}
+
+// Track makes factory reset r on Reset. Stateful generators call it with the factory
+// from context on every generated value, as generator functions can't be inspected.
+// The r must be comparable, usually it's a pointer to the generator state.
+func (f *Factory) Track(r Resettable) {
+	if f != nil && f.resets != nil {
+		f.resets.add(r)
+	}
+}
+
+// Reset resets the stateful generators the factory and its sub-factories have used
+// so far, so that sequences start over and unique values can be produced again.
+// User-supplied generators are untouched unless they implement Resettable and
+// register their state with Factory.Track. The factories derived from each other
+// share the generators, so resetting one resets the others too.
+func (f *Factory) Reset() {
+	f.resets.Reset()
+}
+
+// track registers r with the factory of context if any
+func track(ctx Ctx, r Resettable) {
+	if ctx.Factory != nil {
+		ctx.Factory.Track(r)
+	}
+}
+
+// counter is resettable counter of generated values
+type counter struct {
+	n uint64
+}
+
+// next returns the current counter value and increments it
+func (c *counter) next() uint64 {
+	return atomic.AddUint64(&c.n, 1) - 1
+}
+
+// Reset sets counter to zero
+func (c *counter) Reset() {
+	atomic.StoreUint64(&c.n, 0)
+}
@@ -0,0 +1,103 @@
+package factory_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kolach/go-factory"
+)
+
+type counterState struct {
+	n int
+}
+
+func (c *counterState) Reset() {
+	c.n = 0
+}
+
+type loggedState struct {
+	name string
+	log  *[]string
+}
+
+func (s *loggedState) Reset() {
+	*s.log = append(*s.log, s.name)
+}
+
+var _ = Describe("Reset", func() {
+	usernames := func(users []interface{}) []string {
+		names := []string{}
+		for _, u := range users {
+			names = append(names, u.(*User).Username)
+		}
+		return names
+	}
+
+	It("should start sequences over", func() {
+		f := NewFactory(
+			User{},
+			Use(Sequence(func(n int) interface{} { return fmt.Sprintf("user%d", n) })).For("Username"),
+			Use(SeqSelect("Doe", "Smith", "Roy")).For("LastName"),
+		)
+
+		f.MustCreateN(2)
+		f.Reset()
+
+		u := f.MustCreate().(*User)
+		Ω(u.Username).Should(Equal("user1"))
+		Ω(u.LastName).Should(Equal("Doe"))
+	})
+
+	It("should forget unique values", func() {
+		f := NewFactory(User{}, Use(Unique(SeqSelect("john", "bob"))).For("Username"))
+
+		Ω(usernames(f.MustCreateN(2))).Should(Equal([]string{"john", "bob"}))
+		f.Reset()
+		Ω(usernames(f.MustCreateN(2))).Should(Equal([]string{"john", "bob"}))
+	})
+
+	It("should reset sub-factories", func() {
+		addrFact := NewFactory(Address{}, Use(SeqSelect("CDMX", "Cancun")).For("City"))
+		f := NewFactory(Customer{}, Use(addrFact).For("Address"))
+
+		f.MustCreate()
+		f.Reset()
+		Ω(f.MustCreate().(*Customer).Address.City).Should(Equal("CDMX"))
+	})
+
+	It("should reset tracked user state", func() {
+		state := &counterState{}
+		f := NewFactory(User{}, Use(func(ctx Ctx) (interface{}, error) {
+			ctx.Factory.Track(state)
+			state.n++
+			return state.n, nil
+		}).For("Age"))
+
+		f.MustCreateN(3)
+		f.Reset()
+		Ω(f.MustCreate().(*User).Age).Should(Equal(1))
+	})
+
+	It("should reset tracked state in order of tracking", func() {
+		log := []string{}
+		states := []*loggedState{}
+		for _, name := range []string{"e", "b", "d", "a", "c", "f"} {
+			states = append(states, &loggedState{name: name, log: &log})
+		}
+		f := NewFactory(User{}, Use(func(ctx Ctx) (interface{}, error) {
+			for _, s := range states {
+				ctx.Factory.Track(s)
+			}
+			return nil, nil
+		}).For("Age"))
+
+		f.MustCreateN(2)
+		for i := 0; i < 5; i++ {
+			log = log[:0]
+			f.Reset()
+			Ω(log).Should(Equal([]string{"e", "b", "d", "a", "c", "f"}))
+		}
+	})
+})
@@ -0,0 +1,71 @@
+package factory
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// instancePools keeps the instances released by CreateNReuse for reuse
+var instancePools sync.Map // reflect.Type -> *sync.Pool
+
+// pool returns the pool of instances of the factory type
+func (f *Factory) pool() *sync.Pool {
+	if p, ok := instancePools.Load(f.typ); ok {
+		return p.(*sync.Pool)
+	}
+	typ := f.typ
+	p, _ := instancePools.LoadOrStore(typ, &sync.Pool{New: func() interface{} {
+		return reflect.New(typ).Interface()
+	}})
+	return p.(*sync.Pool)
+}
+
+// reusing returns a copy of factory that refills instances, collectors are dropped
+// as they would keep the same instance many times
+func (f *Factory) reusing() *Factory {
+	d := *f
+	d.collect = nil
+	return &d
+}
+
+// refill zeroes the instance and fills in its fields again
+func (f *Factory) refill(c context.Context, instance reflect.Value) error {
+	instance.Elem().Set(reflect.Zero(f.typ))
+	return f.SetFieldsCtx(c, instance.Interface())
+}
+
+// CreateNReuse is like CreateN but instead of allocating n instances it refills the same pooled one
+// and passes it to fn along with its index, so large batches make little garbage. The instance is
+// zeroed before its fields are generated again. It stops on the first error of the creation or fn
+// and returns it. It returns an error if n is negative.
+//
+// The instance passed to fn is valid until fn returns: the caller must not retain the pointer or
+// pointers to its fields across iterations, copy the instance instead if it is needed later.
+// For the same reason the collectors of the factory, see WithCollector, are not called.
+func (f *Factory) CreateNReuse(n int, fn func(i int, instance interface{}) error, fieldGenFuncs ...FieldGenFunc) error {
+	if err := checkCount(n); err != nil {
+		return err
+	}
+	d, err := f.derive(fieldGenFuncs...)
+	if err != nil {
+		return err
+	}
+	d = d.reusing()
+
+	p := d.pool()
+	ptr := p.Get()
+	defer p.Put(ptr)
+	instance := reflect.ValueOf(ptr)
+
+	c := context.Background()
+	for i := 0; i < n; i++ {
+		if err := d.at(i).refill(c, instance); err != nil {
+			return err
+		}
+		if err := fn(i, ptr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package factory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kolach/go-factory"
+)
+
+var _ = Describe("Reuse", func() {
+	var addrFact *Factory
+
+	BeforeEach(func() {
+		addrFact = NewFactory(Address{}, Use(SeqSelect("a", "b", "c")).For("City"))
+	})
+
+	Context("CreateNReuse", func() {
+		It("should refill the same instance", func() {
+			var first interface{}
+			cities := []string{}
+			err := addrFact.CreateNReuse(4, func(i int, instance interface{}) error {
+				if i == 0 {
+					first = instance
+				}
+				Ω(instance).Should(BeIdenticalTo(first))
+				cities = append(cities, instance.(*Address).City)
+				return nil
+			})
+			Ω(err).Should(BeNil())
+			Ω(cities).Should(Equal([]string{"a", "b", "c", "a"}))
+		})
+
+		It("should zero the instance before refilling it", func() {
+			streets := []string{}
+			err := addrFact.CreateNReuse(3, func(i int, instance interface{}) error {
+				addr := instance.(*Address)
+				streets = append(streets, addr.Street)
+				addr.Street = "Main"
+				return nil
+			}, Use(func(ctx Ctx) (interface{}, error) {
+				if ctx.Index == 1 {
+					return Unset, nil
+				}
+				return "Broad", nil
+			}).For("Street"))
+			Ω(err).Should(BeNil())
+			Ω(streets).Should(Equal([]string{"Broad", "", "Broad"}))
+		})
+
+		It("should stop on error of fn", func() {
+			boom := errors.New("boom")
+			calls := 0
+			err := addrFact.CreateNReuse(5, func(i int, instance interface{}) error {
+				calls++
+				if i == 1 {
+					return boom
+				}
+				return nil
+			})
+			Ω(err).Should(Equal(boom))
+			Ω(calls).Should(Equal(2))
+		})
+
+		It("should return error on negative number of instances", func() {
+			err := addrFact.CreateNReuse(-1, func(int, interface{}) error { return nil })
+			Ω(err).Should(MatchError("expect non-negative number of instances but was: -1"))
+		})
+
+		It("should not call collectors", func() {
+			collected := 0
+			f := addrFact.WithCollector(func(interface{}) { collected++ })
+			Ω(f.CreateNReuse(3, func(int, interface{}) error { return nil })).Should(Succeed())
+			Ω(collected).Should(BeZero())
+
+			f.MustCreateN(2)
+			Ω(collected).Should(Equal(2))
+		})
+
+		It("should return error of invalid overrides", func() {
+			err := addrFact.CreateNReuse(1, func(int, interface{}) error { return nil }, WithGenE(NewGenerator("x"), "Nowhere"))
+			Ω(err).Should(MatchError(`field "Nowhere" not found in Address`))
+		})
+	})
+
+	Context("StreamReuse", func() {
+		It("should refill two instances in turn", func() {
+			c, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			instances, _ := addrFact.StreamReuse(c)
+
+			received := []*Address{}
+			for _, city := range []string{"a", "b", "c", "a"} {
+				addr := (<-instances).(*Address)
+				Ω(addr.City).Should(Equal(city))
+				received = append(received, addr)
+			}
+			Ω(received[0]).ShouldNot(BeIdenticalTo(received[1]))
+			Ω(received[2]).Should(BeIdenticalTo(received[0]))
+			Ω(received[3]).Should(BeIdenticalTo(received[1]))
+		})
+
+		It("should not call collectors", func() {
+			collected := 0
+			f := addrFact.WithCollector(func(interface{}) { collected++ })
+			c, cancel := context.WithCancel(context.Background())
+			instances, _ := f.StreamReuse(c)
+			<-instances
+			<-instances
+			cancel()
+			Eventually(instances).Should(BeClosed())
+			Ω(collected).Should(BeZero())
+		})
+	})
+})
+
+// Batch of instances refilled in place, compare with BenchmarkCreateN
+func BenchmarkCreateNReuse(b *testing.B) {
+	f := NewFactory(User{}, Use("John").For("FirstName"), Use(30).For("Age"))
+	for i := 0; i < b.N; i++ {
+		if err := f.CreateNReuse(100, func(int, interface{}) error { return nil }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Batch of new instances
+func BenchmarkCreateN(b *testing.B) {
+	f := NewFactory(User{}, Use("John").For("FirstName"), Use(30).For("Age"))
+	for i := 0; i < b.N; i++ {
+		f.MustCreateN(100)
+	}
+}
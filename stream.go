@@ -0,0 +1,87 @@
+package factory
+
+import (
+	"context"
+	"reflect"
+)
+
+// Stream creates instances in a goroutine and sends them to the returned channel until
+// the context c is done, so the instances are not kept in memory. The index of instance in
+// the stream is passed to generators as ctx.Index. Stateful generators like Sequence keep
+// advancing across the stream. The instance channel is closed when the stream stops. If the
+// creation fails, the error is sent to the error channel before closing the instance channel.
+// Otherwise the error channel is closed with no error.
+func (f *Factory) Stream(c context.Context, fieldGenFuncs ...FieldGenFunc) (<-chan interface{}, <-chan error) {
+	return f.stream(c, false, fieldGenFuncs)
+}
+
+// StreamReuse is like Stream but refills two pooled instances in turn instead of allocating a new one
+// each time, see CreateNReuse. One instance is filled in while the receiver uses the other, so the
+// instance received is valid until the next one is received: the caller must not retain the pointer
+// or pointers to its fields after that. For the same reason the collectors of the factory are not called.
+// When the stream stops the instance that is not held by the receiver goes back to the pool.
+func (f *Factory) StreamReuse(c context.Context, fieldGenFuncs ...FieldGenFunc) (<-chan interface{}, <-chan error) {
+	return f.stream(c, true, fieldGenFuncs)
+}
+
+// stream starts the goroutine sending instances, refilling two of them in turn if reuse is set:
+// one is being used by the receiver while the other is being filled in
+func (f *Factory) stream(c context.Context, reuse bool, fieldGenFuncs []FieldGenFunc) (<-chan interface{}, <-chan error) {
+	instances := make(chan interface{})
+	errs := make(chan error, 1)
+
+	d, err := f.derive(fieldGenFuncs...)
+	if err != nil {
+		errs <- err
+		close(errs)
+		close(instances)
+		return instances, errs
+	}
+
+	go func() {
+		defer close(instances)
+		defer close(errs)
+
+		var buffers [2]reflect.Value
+		sent := -1 // the buffer sent last, it may still be in use by the receiver
+		if reuse {
+			d = d.reusing()
+			p := d.pool()
+			buffers = [2]reflect.Value{reflect.ValueOf(p.Get()), reflect.ValueOf(p.Get())}
+			defer func() {
+				for i, buf := range buffers {
+					if i != sent {
+						p.Put(buf.Interface())
+					}
+				}
+			}()
+		}
+
+		for i := 0; ; i++ {
+			var instance interface{}
+			var err error
+			if reuse {
+				buf := buffers[i%2]
+				err = d.at(i).refill(c, buf)
+				instance = buf.Interface()
+			} else {
+				instance, err = d.at(i).CreateCtx(c)
+			}
+
+			if err != nil {
+				if c.Err() == nil {
+					errs <- err
+				}
+				return
+			}
+
+			select {
+			case instances <- instance:
+				sent = i % 2
+			case <-c.Done():
+				return
+			}
+		}
+	}()
+	return instances, errs
+}
@@ -0,0 +1,56 @@
+package factory_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kolach/go-factory"
+)
+
+var _ = Describe("Stream", func() {
+	var addrFact *Factory
+
+	BeforeEach(func() {
+		addrFact = NewFactory(Address{}, Use(SeqSelect("a", "b", "c")).For("City"))
+	})
+
+	It("should stream instances until context is done", func() {
+		c, cancel := context.WithCancel(context.Background())
+		instances, errs := addrFact.Stream(c, Use(func(ctx Ctx) (interface{}, error) {
+			return string(rune('0' + ctx.Index)), nil
+		}).For("Street"))
+
+		for i, city := range []string{"a", "b", "c", "a"} {
+			addr := (<-instances).(*Address)
+			Ω(addr.City).Should(Equal(city))
+			Ω(addr.Street).Should(Equal(string(rune('0' + i))))
+		}
+		cancel()
+
+		Eventually(instances).Should(BeClosed())
+		Ω(<-errs).Should(BeNil())
+	})
+
+	It("should send error and stop on failure", func() {
+		boom := errors.New("boom")
+		instances, errs := addrFact.Stream(context.Background(), Use(func(ctx Ctx) (interface{}, error) {
+			if ctx.Index == 1 {
+				return nil, boom
+			}
+			return "Main", nil
+		}).For("Street"))
+
+		Ω(<-instances).ShouldNot(BeNil())
+		Ω(errors.Is(<-errs, boom)).Should(BeTrue())
+		Eventually(instances).Should(BeClosed())
+	})
+
+	It("should send error of invalid overrides", func() {
+		instances, errs := addrFact.Stream(context.Background(), WithGenE(NewGenerator("x"), "Nowhere"))
+		Ω(<-errs).Should(MatchError(`field "Nowhere" not found in Address`))
+		Ω(instances).Should(BeClosed())
+	})
+})
@@ -0,0 +1,231 @@
+package factory
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	randomdata "github.com/Pallinder/go-randomdata"
+)
+
+// tagName is the name of struct tag with generator directives
+const tagName = "factory"
+
+// tagGenBuildersMu guards tagGenBuilders
+var tagGenBuildersMu sync.RWMutex
+
+// tagGenBuilders maps tag directive name to the builder of generator,
+// builder receives the list of directive arguments and the type of tagged field
+var tagGenBuilders = map[string]func(args []string, typ reflect.Type) GeneratorFunc{
+	"email":       buildEmailGen,
+	"uuid":        buildUUIDGen,
+	"intrange":    buildIntRangeGen,
+	"floatrange":  buildFloatRangeGen,
+	"timebetween": buildTimeBetweenGen,
+	"select":      buildSelectGen,
+}
+
+// RegisterTagGen registers the builder of generator for the tag directive name,
+// so `factory:"name:arg1,arg2"` tag makes the generator returned by build([]string{"arg1", "arg2"}).
+// The builder may panic with an error on invalid arguments. Registering a directive
+// with the same name replaces the previous one including built-in directives.
+func RegisterTagGen(name string, build func(args []string) GeneratorFunc) {
+	tagGenBuildersMu.Lock()
+	defer tagGenBuildersMu.Unlock()
+	tagGenBuilders[name] = func(args []string, _ reflect.Type) GeneratorFunc {
+		return build(args)
+	}
+}
+
+// NewFactoryFromTags is factory constructor that makes field generators from `factory` struct tags like:
+//
+//	type User struct {
+//	  ID    string `factory:"uuid"`
+//	  Email string `factory:"email"`
+//	  Age   int    `factory:"intrange:18,65"`
+//	}
+//
+// The generators are made for the fields that are zero in proto object. Explicitly provided
+// field generators win over the tags. Unknown or malformed directive makes it return an error.
+func NewFactoryFromTags(proto interface{}, fieldGenFuncs ...FieldGenFunc) (*Factory, error) {
+	tagGens, err := tagGens(unwrapProto(proto))
+	if err != nil {
+		return nil, err
+	}
+	return NewFactoryE(proto, append(tagGens, fieldGenFuncs...)...)
+}
+
+// tagGens makes field generators from struct tags of proto object zero value fields
+// except the pinned ones
+func tagGens(proto interface{}, pinned []string) ([]FieldGenFunc, error) {
+	val := reflect.ValueOf(proto)
+	typ := val.Type()
+
+	fieldGenFuncs := []FieldGenFunc{}
+	if typ.Kind() != reflect.Struct {
+		// maps have no tags
+		return fieldGenFuncs, nil
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		sField := typ.Field(i)
+		tag, ok := sField.Tag.Lookup(tagName)
+		if !ok || tag == "" || tag == "-" || !val.Field(i).IsZero() || matchFields(sField.Name, pinned) {
+			continue
+		}
+
+		g, err := parseTag(tag, sField.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %v", sField.Name, err)
+		}
+		fieldGenFuncs = append(fieldGenFuncs, WithGenE(g, sField.Name).kinded(kindTag))
+	}
+	return fieldGenFuncs, nil
+}
+
+// parseTag makes generator from tag directive like "name:arg1,arg2" for the field of type typ
+func parseTag(tag string, typ reflect.Type) (g GeneratorFunc, err error) {
+	name, args := tag, []string{}
+	if i := strings.Index(tag, ":"); i >= 0 {
+		name, args = tag[:i], strings.Split(tag[i+1:], ",")
+	}
+
+	tagGenBuildersMu.RLock()
+	build, ok := tagGenBuilders[name]
+	tagGenBuildersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown tag directive %q", name)
+	}
+
+	// generator constructors panic on invalid arguments
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(error); ok {
+				err = fmt.Errorf("invalid tag directive %q: %v", tag, e)
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	return build(args, typ), nil
+}
+
+// expectArgs panics if number of directive arguments is not n
+func expectArgs(args []string, n int) {
+	if len(args) != n {
+		panic(fmt.Errorf("expect %d arguments but was: %d", n, len(args)))
+	}
+}
+
+func buildEmailGen(args []string, _ reflect.Type) GeneratorFunc {
+	expectArgs(args, 0)
+	return func(Ctx) (interface{}, error) {
+		return randomdata.Email(), nil
+	}
+}
+
+func buildUUIDGen(args []string, _ reflect.Type) GeneratorFunc {
+	expectArgs(args, 0)
+	return UUIDv4()
+}
+
+func buildIntRangeGen(args []string, _ reflect.Type) GeneratorFunc {
+	expectArgs(args, 2)
+	min, err := strconv.Atoi(args[0])
+	if err != nil {
+		panic(err)
+	}
+	max, err := strconv.Atoi(args[1])
+	if err != nil {
+		panic(err)
+	}
+	return IntRange(min, max)
+}
+
+func buildFloatRangeGen(args []string, _ reflect.Type) GeneratorFunc {
+	expectArgs(args, 2)
+	min, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		panic(err)
+	}
+	max, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		panic(err)
+	}
+	return FloatRange(min, max)
+}
+
+func buildTimeBetweenGen(args []string, _ reflect.Type) GeneratorFunc {
+	expectArgs(args, 2)
+	start, err := time.Parse(time.RFC3339, args[0])
+	if err != nil {
+		panic(err)
+	}
+	end, err := time.Parse(time.RFC3339, args[1])
+	if err != nil {
+		panic(err)
+	}
+	return TimeBetween(start, end)
+}
+
+// buildSelectGen makes generator that randomly picks one of the options parsed
+// into values of the field type, so the options not matching the type are reported
+// on factory construction. Options for pointer fields are parsed into the pointed type.
+func buildSelectGen(args []string, typ reflect.Type) GeneratorFunc {
+	if len(args) == 0 {
+		panic(fmt.Errorf("expect at least one option to select from"))
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	options := make([]interface{}, len(args))
+	for i, arg := range args {
+		val, err := parseValue(arg, typ)
+		if err != nil {
+			panic(err)
+		}
+		options[i] = val
+	}
+	return func(ctx Ctx) (interface{}, error) {
+		return options[randIntn(ctx, len(options))], nil
+	}
+}
+
+// parseValue parses string into a value of basic kind type
+func parseValue(s string, typ reflect.Type) (interface{}, error) {
+	val := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.String:
+		val.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, err
+		}
+		val.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		val.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		val.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(s, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		val.SetFloat(x)
+	default:
+		return nil, fmt.Errorf("can not parse %q into %s", s, typ)
+	}
+	return val.Interface(), nil
+}
@@ -0,0 +1,135 @@
+package factory_test
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kolach/go-factory"
+	. "github.com/kolach/gomega-matchers"
+)
+
+type Tagged struct {
+	ID        string    `factory:"uuid"`
+	Email     string    `factory:"email"`
+	Age       int       `factory:"intrange:18,65"`
+	Score     float64   `factory:"floatrange:0,1"`
+	CreatedAt time.Time `factory:"timebetween:2019-01-01T00:00:00Z,2019-02-01T00:00:00Z"`
+	Role      string    `factory:"select:admin,user"`
+	Level     uint8     `factory:"select:1,2"`
+	Comment   string    `factory:"-"`
+	Name      string
+}
+
+var _ = Describe("NewFactoryFromTags", func() {
+	It("should make generators from tags", func() {
+		f, err := NewFactoryFromTags(Tagged{})
+		Ω(err).Should(BeNil())
+
+		t := f.MustCreate().(*Tagged)
+		Ω(t.ID).Should(MatchRegexp(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`))
+		Ω(t.Email).Should(ContainSubstring("@"))
+		Ω(t.Age).Should(And(BeNumerically(">=", 18), BeNumerically("<", 65)))
+		Ω(t.Score).Should(And(BeNumerically(">=", 0), BeNumerically("<", 1)))
+		Ω(t.CreatedAt.Year()).Should(Equal(2019))
+		Ω(t.Role).Should(BelongTo("admin", "user"))
+		Ω(t.Level).Should(BelongTo(uint8(1), uint8(2)))
+		Ω(t.Comment).Should(BeEmpty())
+	})
+
+	It("should let proto values and explicit generators win", func() {
+		f, err := NewFactoryFromTags(Tagged{Age: 99}, Use("root").For("Role"))
+		Ω(err).Should(BeNil())
+
+		t := f.MustCreate().(*Tagged)
+		Ω(t.Age).Should(Equal(99))
+		Ω(t.Role).Should(Equal("root"))
+	})
+
+	It("should not make generators for pinned proto fields", func() {
+		f, err := NewFactoryFromTags(Proto(Tagged{}, "Age"))
+		Ω(err).Should(BeNil())
+		Ω(f.MustCreate().(*Tagged).Age).Should(BeZero())
+	})
+
+	It("should return error on unknown directive", func() {
+		type T struct {
+			Name string `factory:"foobar"`
+		}
+		_, err := NewFactoryFromTags(T{})
+		Ω(err).Should(MatchError(`field "Name": unknown tag directive "foobar"`))
+	})
+
+	It("should return error on malformed directive", func() {
+		type T struct {
+			Age int `factory:"intrange:65,18"`
+		}
+		_, err := NewFactoryFromTags(T{})
+		Ω(err).Should(MatchError(`field "Age": invalid tag directive "intrange:65,18": expect min to be less than max but was: [65, 18)`))
+	})
+
+	It("should return error on select options not matching field type", func() {
+		type T struct {
+			Level int `factory:"select:a,b"`
+		}
+		_, err := NewFactoryFromTags(T{})
+		Ω(err).Should(MatchError(`field "Level": invalid tag directive "select:a,b": strconv.ParseInt: parsing "a": invalid syntax`))
+
+		type S struct {
+			Tags []string `factory:"select:a,b"`
+		}
+		_, err = NewFactoryFromTags(S{})
+		Ω(err).Should(MatchError(`field "Tags": invalid tag directive "select:a,b": can not parse "a" into []string`))
+	})
+
+	It("should select options for pointer fields", func() {
+		type T struct {
+			Level *int `factory:"select:1"`
+		}
+		f, err := NewFactoryFromTags(T{})
+		Ω(err).Should(BeNil())
+		Ω(*f.MustCreate().(*T).Level).Should(Equal(1))
+	})
+
+	It("should return error on tagged unexported field", func() {
+		type T struct {
+			age int `factory:"intrange:18,65"`
+		}
+		_, err := NewFactoryFromTags(T{})
+		Ω(err).Should(MatchError(`field "age" can not be set in T`))
+	})
+
+	Describe("RegisterTagGen", func() {
+		It("should use registered directive", func() {
+			RegisterTagGen("tenant", func(args []string) GeneratorFunc {
+				return NewGenerator("tenant-" + args[0])
+			})
+
+			type T struct {
+				Tenant string `factory:"tenant:acme"`
+			}
+			f, err := NewFactoryFromTags(T{})
+			Ω(err).Should(BeNil())
+			Ω(f.MustCreate().(*T).Tenant).Should(Equal("tenant-acme"))
+		})
+
+		It("should be safe to register directives concurrently", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer GinkgoRecover()
+					defer wg.Done()
+					name := fmt.Sprintf("concurrent%d", i)
+					RegisterTagGen(name, func(args []string) GeneratorFunc { return NewGenerator(name) })
+					_, err := NewFactoryFromTags(Tagged{})
+					Ω(err).Should(BeNil())
+				}(i)
+			}
+			wg.Wait()
+		})
+	})
+})
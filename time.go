@@ -0,0 +1,55 @@
+package factory
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeBetween randomly generates time in interval [start, end).
+// The generator returns *time.Time so it can be used for both time.Time and *time.Time fields.
+func TimeBetween(start, end time.Time) GeneratorFunc {
+	if !start.Before(end) {
+		panic(fmt.Errorf("expect start to be before end but was: [%v, %v)", start, end))
+	}
+	d := end.Sub(start)
+	return func(ctx Ctx) (interface{}, error) {
+		t := start.Add(time.Duration(randInt63n(ctx, int64(d))))
+		return &t, nil
+	}
+}
+
+// RelativeTime randomly generates time in interval [base+min, base+max).
+// For example RelativeTime(time.Now(), -30*24*time.Hour, 0) makes time in the last 30 days.
+func RelativeTime(base time.Time, min, max time.Duration) GeneratorFunc {
+	if min >= max {
+		panic(fmt.Errorf("expect min to be less than max but was: [%v, %v)", min, max))
+	}
+	return TimeBetween(base.Add(min), base.Add(max))
+}
+
+// TimeSeq generates increasing time start, start+step, start+2*step, ... on each call,
+// for example to make ordered event logs with CreateN. It is safe to use concurrently.
+// The sequence starts over on Factory.Reset. Like TimeBetween the generator returns *time.Time
+// so it can be used for both time.Time and *time.Time fields.
+func TimeSeq(start time.Time, step time.Duration) GeneratorFunc {
+	if step <= 0 {
+		panic(fmt.Errorf("expect positive step but was: %v", step))
+	}
+	c := &counter{}
+	return func(ctx Ctx) (interface{}, error) {
+		track(ctx, c)
+		t := start.Add(time.Duration(c.next()) * step)
+		return &t, nil
+	}
+}
+
+// Duration randomly generates duration in interval [min, max). The value can be assigned
+// to time.Duration fields as well as to the fields of other integer types as nanoseconds.
+func Duration(min, max time.Duration) GeneratorFunc {
+	if min >= max {
+		panic(fmt.Errorf("expect min to be less than max but was: [%v, %v)", min, max))
+	}
+	return func(ctx Ctx) (interface{}, error) {
+		return min + time.Duration(randInt63n(ctx, int64(max-min))), nil
+	}
+}
@@ -0,0 +1,146 @@
+package factory_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kolach/go-factory"
+	. "github.com/kolach/gomega-matchers"
+)
+
+type Event struct {
+	CreatedAt time.Time
+	UpdatedAt *time.Time
+}
+
+type Cents int64
+
+type Job struct {
+	Timeout   time.Duration
+	Backoff   time.Duration
+	TimeoutNs int64
+	Price     Cents
+}
+
+var _ = Describe("time generators", func() {
+	var (
+		start = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+		end   = time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	Describe("TimeBetween", func() {
+		It("should generate time and pointer to time fields", func() {
+			f := NewFactory(Event{}, Use(TimeBetween(start, end)).For("CreatedAt", "UpdatedAt"))
+			for i := 0; i < 100; i++ {
+				e := f.MustCreate().(*Event)
+				Ω(e.CreatedAt).Should(BeTemporally(">=", start))
+				Ω(e.CreatedAt).Should(BeTemporally("<", end))
+				Ω(e.UpdatedAt).ShouldNot(BeNil())
+				Ω(*e.UpdatedAt).Should(BeTemporally(">=", start))
+				Ω(*e.UpdatedAt).Should(BeTemporally("<", end))
+			}
+		})
+
+		It("should panic if start is not before end", func() {
+			Ω(func() { TimeBetween(end, start) }).Should(PanicWithError(errors.New(
+				"expect start to be before end but was: [2019-02-01 00:00:00 +0000 UTC, 2019-01-01 00:00:00 +0000 UTC)",
+			)))
+		})
+	})
+
+	Describe("RelativeTime", func() {
+		It("should generate time relative to base", func() {
+			f := NewFactory(Event{}, Use(RelativeTime(end, -24*time.Hour, 0)).For("CreatedAt"))
+			e := f.MustCreate().(*Event)
+			Ω(e.CreatedAt).Should(BeTemporally(">=", end.Add(-24*time.Hour)))
+			Ω(e.CreatedAt).Should(BeTemporally("<", end))
+		})
+
+		It("should panic if min is not less than max", func() {
+			Ω(func() { RelativeTime(end, time.Hour, 0) }).Should(PanicWithError(errors.New("expect min to be less than max but was: [1h0m0s, 0s)")))
+		})
+	})
+
+	Describe("TimeSeq", func() {
+		It("should generate increasing time", func() {
+			f := NewFactory(Event{}, Use(TimeSeq(start, time.Minute)).For("CreatedAt", "UpdatedAt"))
+			events := f.MustCreateN(3)
+			for i, e := range events {
+				Ω(e.(*Event).CreatedAt).Should(Equal(start.Add(time.Duration(2*i) * time.Minute)))
+				Ω(*e.(*Event).UpdatedAt).Should(Equal(start.Add(time.Duration(2*i+1) * time.Minute)))
+			}
+		})
+
+		It("should start over on reset", func() {
+			f := NewFactory(Event{}, Use(TimeSeq(start, time.Second)).For("CreatedAt"))
+			f.MustCreateN(5)
+			f.Reset()
+			Ω(f.MustCreate().(*Event).CreatedAt).Should(Equal(start))
+		})
+
+		It("should be safe for concurrent use", func() {
+			gen := TimeSeq(start, time.Second)
+			var wg sync.WaitGroup
+			times := make(chan time.Time, 100)
+			for i := 0; i < 100; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					t, _ := gen(Ctx{})
+					times <- *t.(*time.Time)
+				}()
+			}
+			wg.Wait()
+			close(times)
+
+			seen := map[time.Time]bool{}
+			for t := range times {
+				seen[t] = true
+			}
+			Ω(seen).Should(HaveLen(100))
+		})
+
+		It("should panic on not positive step", func() {
+			Ω(func() { TimeSeq(start, 0) }).Should(PanicWithError(errors.New("expect positive step but was: 0s")))
+		})
+	})
+
+	Describe("Duration", func() {
+		It("should generate durations in interval", func() {
+			f := NewFactory(Job{}, Use(Duration(time.Second, time.Minute)).For("Timeout", "TimeoutNs"))
+			for i := 0; i < 100; i++ {
+				j := f.MustCreate().(*Job)
+				Ω(j.Timeout).Should(BeNumerically(">=", time.Second))
+				Ω(j.Timeout).Should(BeNumerically("<", time.Minute))
+				Ω(j.TimeoutNs).Should(BeNumerically(">=", int64(time.Second)))
+				Ω(j.TimeoutNs).Should(BeNumerically("<", int64(time.Minute)))
+			}
+		})
+
+		It("should panic if min is not less than max", func() {
+			Ω(func() { Duration(time.Minute, time.Second) }).Should(PanicWithError(errors.New("expect min to be less than max but was: [1m0s, 1s)")))
+		})
+	})
+
+	Describe("named numeric types", func() {
+		It("should convert generated values", func() {
+			j := NewFactory(Job{},
+				Use(5000).For("Timeout"),
+				Use(func() int64 { return int64(time.Second) }).For("Backoff"),
+				Use(199).For("Price"),
+			).MustCreate().(*Job)
+			Ω(j.Timeout).Should(Equal(5000 * time.Nanosecond))
+			Ω(j.Backoff).Should(Equal(time.Second))
+			Ω(j.Price).Should(Equal(Cents(199)))
+		})
+
+		It("should return error on values that can't be converted", func() {
+			_, err := NewFactory(Job{}, Use("5s").For("Timeout")).Create()
+			Ω(err).Should(MatchError(`field "Timeout": cannot assign string to time.Duration`))
+		})
+	})
+})
@@ -0,0 +1,25 @@
+package factory
+
+import "reflect"
+
+// RegisterTrait registers a named set of field generators that can be applied
+// on top of the factory generators with WithTraits.
+func (f *Factory) RegisterTrait(name string, fieldGenFuncs ...FieldGenFunc) *Factory {
+	// copy traits so the registration does not leak into derived factories
+	traits := make(map[string][]FieldGenFunc, len(f.traits)+1)
+	for k, v := range f.traits {
+		traits[k] = v
+	}
+	traits[name] = fieldGenFuncs
+	f.traits = traits
+	return f
+}
+
+// WithTraits applies the field generators of named traits. Traits are applied in order,
+// so the later trait wins on conflicting fields, and before any other field generator
+// passed along with them.
+func WithTraits(names ...string) FieldGenFunc {
+	return func(reflect.Value) []fieldWithGen {
+		return []fieldWithGen{{traits: names}}
+	}
+}
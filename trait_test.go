@@ -0,0 +1,66 @@
+package factory_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kolach/go-factory"
+	. "github.com/kolach/gomega-matchers"
+)
+
+var _ = Describe("Traits", func() {
+	var userFact *Factory
+
+	BeforeEach(func() {
+		userFact = NewFactory(
+			User{},
+			Use("john").For("Username"),
+			Use(30).For("Age"),
+			Use(false).For("Married"),
+		).RegisterTrait(
+			"married",
+			Use(true).For("Married"),
+			Use("Doe").For("LastName"),
+		).RegisterTrait(
+			"senior",
+			Use(70).For("Age"),
+			Use("Smith").For("LastName"),
+		)
+	})
+
+	It("should apply trait generators", func() {
+		u := userFact.MustCreate(WithTraits("married")).(*User)
+		Ω(u.Username).Should(Equal("john"))
+		Ω(u.Married).Should(BeTrue())
+		Ω(u.LastName).Should(Equal("Doe"))
+		Ω(u.Age).Should(Equal(30))
+	})
+
+	It("should compose traits with later trait winning", func() {
+		u := userFact.MustCreate(WithTraits("married", "senior")).(*User)
+		Ω(u.Married).Should(BeTrue())
+		Ω(u.Age).Should(Equal(70))
+		Ω(u.LastName).Should(Equal("Smith"))
+	})
+
+	It("should apply inline overrides on top of traits", func() {
+		u := userFact.MustCreate(Use("Roy").For("LastName"), WithTraits("married")).(*User)
+		Ω(u.Married).Should(BeTrue())
+		Ω(u.LastName).Should(Equal("Roy"))
+	})
+
+	It("should return error on unknown trait", func() {
+		_, err := userFact.Create(WithTraits("admin"))
+		Ω(err).Should(MatchError(`trait "admin" not found in User factory`))
+		Ω(func() { userFact.Derive(WithTraits("admin")) }).Should(PanicWithError(errors.New(`trait "admin" not found in User factory`)))
+	})
+
+	It("should not leak traits into parent factory", func() {
+		f := userFact.Derive().RegisterTrait("admin", Use("admin").For("Username"))
+		Ω(f.MustCreate(WithTraits("admin")).(*User).Username).Should(Equal("admin"))
+		_, err := userFact.Create(WithTraits("admin"))
+		Ω(err).ShouldNot(BeNil())
+	})
+})
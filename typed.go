@@ -0,0 +1,66 @@
+package factory
+
+import "fmt"
+
+// TypedFactory is a type safe wrapper around Factory that produces *T instances
+type TypedFactory[T any] struct {
+	factory *Factory
+}
+
+// NewTyped is typed factory constructor. The zero value of T is used as a proto object.
+func NewTyped[T any](fieldGenFuncs ...FieldGenFunc) *TypedFactory[T] {
+	var proto T
+	return &TypedFactory[T]{factory: NewFactory(proto, fieldGenFuncs...)}
+}
+
+// Factory returns the underlying untyped factory
+func (tf *TypedFactory[T]) Factory() *Factory {
+	return tf.factory
+}
+
+// Derive produces a new typed factory overriding field generators
+// with the list provided.
+func (tf *TypedFactory[T]) Derive(fieldGenFuncs ...FieldGenFunc) *TypedFactory[T] {
+	return &TypedFactory[T]{factory: tf.factory.Derive(fieldGenFuncs...)}
+}
+
+// SetFields fills in the instance fields
+func (tf *TypedFactory[T]) SetFields(i *T, fieldGenFuncs ...FieldGenFunc) error {
+	return tf.factory.SetFields(i, fieldGenFuncs...)
+}
+
+// MustSetFields calls SetFields and panics on error
+func (tf *TypedFactory[T]) MustSetFields(i *T, fieldGenFuncs ...FieldGenFunc) {
+	tf.factory.MustSetFields(i, fieldGenFuncs...)
+}
+
+// Create makes a new instance
+func (tf *TypedFactory[T]) Create(fieldGenFuncs ...FieldGenFunc) (*T, error) {
+	i, err := tf.factory.Create(fieldGenFuncs...)
+	if err != nil {
+		return nil, err
+	}
+	return i.(*T), nil
+}
+
+// MustCreate creates or panics
+func (tf *TypedFactory[T]) MustCreate(fieldGenFuncs ...FieldGenFunc) *T {
+	t, err := tf.Create(fieldGenFuncs...)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// TypedGen adapts generator function fn taking the instance being created as *T, so the instance
+// is asserted once instead of in every generator. The generator returns an error if the instance
+// is not of type *T, for example if it's used by the factory of another type.
+func TypedGen[T any](fn func(ctx Ctx, inst *T) (interface{}, error)) GeneratorFunc {
+	return func(ctx Ctx) (interface{}, error) {
+		inst, ok := ctx.Instance.(*T)
+		if !ok {
+			return nil, fieldError(ctx.Field, fmt.Errorf("expect instance of type %T but was: %T", inst, ctx.Instance))
+		}
+		return fn(ctx, inst)
+	}
+}
@@ -0,0 +1,79 @@
+package factory_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kolach/go-factory"
+)
+
+var _ = Describe("TypedFactory", func() {
+	var userFact *TypedFactory[User]
+
+	BeforeEach(func() {
+		userFact = NewTyped[User](
+			Use("john").For("Username"),
+			Use(30).For("Age"),
+		)
+	})
+
+	It("should create typed instances", func() {
+		u, err := userFact.Create()
+		Ω(err).Should(BeNil())
+		Ω(u.Username).Should(Equal("john"))
+		Ω(u.Age).Should(Equal(30))
+	})
+
+	It("should allow override existing generators on create", func() {
+		u := userFact.MustCreate(Use("jane").For("Username"))
+		Ω(u.Username).Should(Equal("jane"))
+		Ω(u.Age).Should(Equal(30))
+	})
+
+	It("should derive typed factories", func() {
+		u := userFact.Derive(Use(45).For("Age")).MustCreate()
+		Ω(u.Username).Should(Equal("john"))
+		Ω(u.Age).Should(Equal(45))
+	})
+
+	It("should set fields of typed instance", func() {
+		var u User
+		userFact.MustSetFields(&u)
+		Ω(u.Username).Should(Equal("john"))
+	})
+
+	It("should return error from generators", func() {
+		u, err := userFact.Create(Use(func() (string, error) {
+			return "", errors.New("boom")
+		}).For("Username"))
+		Ω(err).Should(MatchError(`field "Username": boom`))
+		Ω(u).Should(BeNil())
+	})
+
+	Describe("TypedGen", func() {
+		email := TypedGen(func(ctx Ctx, u *User) (interface{}, error) {
+			return u.Username + "@mail.com", nil
+		})
+
+		It("should pass typed instance to generator", func() {
+			u := userFact.MustCreate(Use(email).For("Email").DependsOn("Username"))
+			Ω(u.Email).Should(Equal("john@mail.com"))
+
+			untyped := NewFactory(User{}, Use("jane").For("Username"), Use(email).For("Email"))
+			Ω(untyped.MustCreate().(*User).Email).Should(Equal("jane@mail.com"))
+		})
+
+		It("should return error on instance of other type", func() {
+			_, err := NewFactory(Address{}, Use(email).For("City")).Create()
+			Ω(err).Should(MatchError(`field "City": expect instance of type *factory_test.User but was: *factory_test.Address`))
+		})
+
+		It("should return error of generator", func() {
+			boom := TypedGen(func(Ctx, *User) (interface{}, error) { return nil, errors.New("boom") })
+			_, err := userFact.Create(Use(boom).For("Email"))
+			Ω(err).Should(MatchError(`field "Email": boom`))
+		})
+	})
+})
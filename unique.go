@@ -0,0 +1,89 @@
+package factory
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// DefaultUniqueRetries is the number of times Unique invokes generator
+// to get a value that was not produced before.
+const DefaultUniqueRetries = 100
+
+// Unique wraps generator g to produce values that were not produced before
+// for the same field. See UniqueWithRetries.
+func Unique(g GeneratorFunc) GeneratorFunc {
+	return UniqueWithRetries(g, DefaultUniqueRetries)
+}
+
+// UniqueWithRetries wraps generator g to produce values that were not produced before
+// for the same field. The generator g is invoked up to retries times until it yields
+// an unseen value, otherwise an error is returned. The set of seen values belongs to the
+// returned generator, so it's shared by all the factories and calls the generator is used in.
+// It's cleared on Factory.Reset.
+func UniqueWithRetries(g GeneratorFunc, retries int) GeneratorFunc {
+	seen := &seenValues{}
+
+	return func(ctx Ctx) (interface{}, error) {
+		track(ctx, seen)
+		for i := 0; i < retries; i++ {
+			val, err := g(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			if val != nil && !reflect.TypeOf(val).Comparable() {
+				return nil, fieldError(ctx.Field, fmt.Errorf("value of type %T can not be checked for uniqueness", val))
+			}
+
+			added, err := seen.add(ctx.Field, val)
+			if err != nil {
+				return nil, fieldError(ctx.Field, err)
+			}
+			if added {
+				return val, nil
+			}
+		}
+		return nil, fieldError(ctx.Field, fmt.Errorf("no unique value after %d retries", retries))
+	}
+}
+
+// seenValues is a set of values produced per field
+type seenValues struct {
+	mu     sync.Mutex
+	fields map[string]map[interface{}]struct{}
+}
+
+// add puts value of the field into the set, it returns false if the value is already there.
+// Values of comparable types may still hold not comparable values, like a struct with interface
+// field set to a slice, hashing them panics and the panic is returned as an error.
+func (s *seenValues) add(field string, val interface{}) (added bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("value of type %T can not be checked for uniqueness: %v", val, r)
+		}
+	}()
+
+	if s.fields == nil {
+		s.fields = make(map[string]map[interface{}]struct{})
+	}
+	values, ok := s.fields[field]
+	if !ok {
+		values = make(map[interface{}]struct{})
+		s.fields[field] = values
+	}
+	if _, dup := values[val]; dup {
+		return false, nil
+	}
+	values[val] = struct{}{}
+	return true, nil
+}
+
+// Reset forgets all the seen values
+func (s *seenValues) Reset() {
+	s.mu.Lock()
+	s.fields = nil
+	s.mu.Unlock()
+}
@@ -0,0 +1,54 @@
+package factory_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/kolach/go-factory"
+)
+
+var _ = Describe("Unique", func() {
+	It("should produce unique values across calls and batches", func() {
+		f := NewFactory(User{}, Use(Unique(RndSelect("john", "james", "bob", "paul"))).For("Username"))
+
+		users := f.MustCreateN(3)
+		last := f.MustCreate().(*User)
+
+		names := []string{last.Username}
+		for _, u := range users {
+			names = append(names, u.(*User).Username)
+		}
+		Ω(names).Should(ConsistOf("john", "james", "bob", "paul"))
+	})
+
+	It("should track values per field", func() {
+		f := NewFactory(User{}, Use(Unique(SeqSelect("john"))).For("Username", "FirstName"))
+		u := f.MustCreate().(*User)
+		Ω(u.Username).Should(Equal("john"))
+		Ω(u.FirstName).Should(Equal("john"))
+	})
+
+	It("should return error when out of unique values", func() {
+		f := NewFactory(User{}, Use(UniqueWithRetries(SeqSelect("john"), 5)).For("Username"))
+		_, err := f.Create()
+		Ω(err).Should(BeNil())
+		_, err = f.Create()
+		Ω(err).Should(MatchError(`field "Username": no unique value after 5 retries`))
+	})
+
+	It("should return error on uncomparable values", func() {
+		f := NewFactory(S{}, Use(Unique(SeqSelect([]int{1}))).For("Slice"))
+		_, err := f.Create()
+		Ω(err).Should(MatchError(`field "Slice": value of type []int can not be checked for uniqueness`))
+	})
+
+	It("should return error on comparable values holding uncomparable ones", func() {
+		type Box struct{ Value interface{} }
+		type Crate struct{ Box Box }
+		f := NewFactory(Crate{}, Use(Unique(SeqSelect(Box{Value: []int{1}}))).For("Box"))
+		_, err := f.Create()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(HavePrefix(`field "Box": value of type factory_test.Box can not be checked for uniqueness: `))
+		Ω(err.Error()).Should(ContainSubstring("[]int"))
+	})
+})